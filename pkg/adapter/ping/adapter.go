@@ -18,115 +18,2490 @@ package ping
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"text/template"
 	"time"
+	"unicode"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	ceextensions "github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/google/uuid"
 	"github.com/robfig/cron/v3"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/logging"
+	"sigs.k8s.io/yaml"
 
 	"knative.dev/eventing/pkg/adapter/v2"
 	sourcesv1alpha2 "knative.dev/eventing/pkg/apis/sources/v1alpha2"
+	"knative.dev/eventing/pkg/tracing"
+)
+
+const (
+	// sequenceExtension is the CloudEvent extension carrying the sequence
+	// number of the event, starting at 1.
+	sequenceExtension = "sequence"
+
+	// partitionKeyExtension is the CloudEvent extension carrying the
+	// PartitionKey.
+	partitionKeyExtension = "partitionkey"
+
+	// errorDestExtension and errorCodeExtension are the CloudEvent
+	// extensions describing why an event was sent to the dead letter sink.
+	errorDestExtension = "knativeerrordest"
+	errorCodeExtension = "knativeerrorcode"
+
+	// octetStream is the content type of binary data.
+	octetStream = "application/octet-stream"
+)
+
+// secondsParser parses the schedules with a leading seconds field.
+var secondsParser = cron.NewParser(
+	cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
 )
 
 type envConfig struct {
 	adapter.EnvConfig
 
-	// Environment variable container schedule.
-	Schedule string `envconfig:"SCHEDULE" required:"true"`
+	// Environment variable container schedule.
+	Schedule string `envconfig:"SCHEDULE" required:"true"`
+
+	// Environment variable containing data.
+	Data string `envconfig:"DATA"`
+
+	// Environment variable containing the IANA time zone the schedule is evaluated in.
+	Timezone string `envconfig:"TIMEZONE"`
+
+	// Environment variable containing the type of the events to send.
+	EventType string `envconfig:"EVENT_TYPE"`
+
+	// Environment variable containing the source of the events to send.
+	EventSource string `envconfig:"EVENT_SOURCE"`
+
+	// Environment variable containing the maximum random delay applied before each send.
+	JitterMax time.Duration `envconfig:"JITTER_MAX"`
+
+	// Environment variable indicating whether to send a single event and exit.
+	RunOnce bool `envconfig:"RUN_ONCE" default:"false"`
+
+	// Environment variable containing the RFC3339 time after which no more events are sent.
+	EndTime time.Time `envconfig:"END_TIME"`
+
+	// Environment variable containing the RFC3339 time before which no events are sent.
+	StartTime time.Time `envconfig:"START_TIME"`
+
+	// Environment variable containing the duration to wait on startup before starting the schedule.
+	StartupDelay time.Duration `envconfig:"STARTUP_DELAY" default:"0s"`
+
+	// Environment variable indicating whether to skip a tick while the previous one is still running.
+	SkipIfRunning bool `envconfig:"SKIP_IF_RUNNING" default:"true"`
+
+	// Environment variable containing the number of ticks queued for a single sender. Zero disables the queue.
+	QueueSize int `envconfig:"QUEUE_SIZE" default:"0"`
+
+	// Environment variable containing which tick is dropped when the queue is full: drop-oldest or drop-newest.
+	QueueFullPolicy queueFullPolicy `envconfig:"QUEUE_FULL_POLICY" default:"drop-oldest"`
+
+	// Environment variable containing the maximum number of retries of a failed send.
+	// The default keeps the retries of a send under one minute.
+	RetryCount int `envconfig:"RETRY_COUNT" default:"5"`
+
+	// Environment variable containing the exponential backoff period between retries.
+	RetryBackoff time.Duration `envconfig:"RETRY_BACKOFF" default:"50ms"`
+
+	// Environment variable containing the comma-separated HTTP status codes and classes, such as 5xx, of the retried sends.
+	RetryableStatus retryableStatus `envconfig:"RETRYABLE_STATUS"`
+
+	// Environment variable containing the maximum duration of a send, retries included.
+	SendTimeout time.Duration `envconfig:"SEND_TIMEOUT"`
+
+	// Environment variable containing the path of a file to read the data from, instead of DATA.
+	DataFromFile string `envconfig:"DATA_FROM_FILE"`
+
+	// Environment variable indicating whether to read DATA_FROM_FILE again on every tick.
+	DataReload bool `envconfig:"DATA_RELOAD" default:"false"`
+
+	// Environment variable indicating whether to stream DATA_FROM_FILE to the sink instead of reading it in memory.
+	DataStream bool `envconfig:"DATA_STREAM" default:"false"`
+
+	// Environment variable containing the content type of the data.
+	DataContentType string `envconfig:"DATA_CONTENT_TYPE"`
+
+	// Environment variable indicating whether the data is base64 encoded.
+	DataBase64 bool `envconfig:"DATA_BASE64" default:"false"`
+
+	// Environment variable containing the subject of the events to send.
+	Subject string `envconfig:"SUBJECT"`
+
+	// Environment variable containing the partition key of the events to send.
+	PartitionKey string `envconfig:"PARTITION_KEY"`
+	// Environment variable containing a JSON object of CloudEvent extensions to set on the events, other than those set by the adapter.
+	// Environment variable containing a JSON object of CloudEvent extensions to set on the events.
+	Extensions extensions `envconfig:"EXTENSIONS"`
+
+	// Environment variable indicating whether the data is a text/template.
+	DataTemplate bool `envconfig:"DATA_TEMPLATE" default:"false"`
+
+	// Environment variable containing the port of the health probes server.
+	ProbePort int `envconfig:"PROBE_PORT"`
+
+	// Environment variable containing the minimum interval between two ticks of the schedule.
+	MinInterval time.Duration `envconfig:"MIN_INTERVAL" default:"1s"`
+
+	// Environment variable containing the maximum duration to wait for in-flight sends on shutdown.
+	DrainTimeout time.Duration `envconfig:"DRAIN_TIMEOUT" default:"10s"`
+
+	// Environment variable containing a comma-separated list of sink URIs to send the events to.
+	Sinks []string `envconfig:"SINKS"`
+
+	// Environment variable containing a comma-separated list of the CloudEvents spec versions of the events sent to each of SINKS, in order. Empty entries default to CE_SPEC_VERSION.
+	SinkSpecVersions sinkSpecVersions `envconfig:"SINK_SPEC_VERSIONS"`
+
+	// Environment variable containing the URI of the sink receiving the events which failed to be sent.
+	DeadLetterSink string `envconfig:"DEAD_LETTER_SINK"`
+
+	// Environment variable containing the HTTP encoding of the events, binary or structured.
+	Encoding encoding `envconfig:"ENCODING"`
+
+	// Environment variable containing the encoding, binary or structured, of the events rejected for their encoding.
+	FallbackEncoding encoding `envconfig:"FALLBACK_ENCODING"`
+
+	// Environment variable containing the compression of the request bodies, none or gzip.
+	Compression compression `envconfig:"COMPRESSION" default:"none"`
+
+	// Environment variable indicating whether to send one event per element of the DATA JSON array.
+	Batch bool `envconfig:"BATCH" default:"false"`
+
+	// Environment variable indicating whether the schedule starts with a seconds field.
+	SecondsField bool `envconfig:"SECONDS_FIELD" default:"false"`
+
+	// Environment variable containing the dataschema URI of the events to send.
+	DataSchema string `envconfig:"DATA_SCHEMA"`
+
+	// Environment variable containing an inline JSON Schema, or the path of a file containing one, the data must conform to.
+	ValidateSchema string `envconfig:"VALIDATE_SCHEMA"`
+
+	// Environment variable containing the format of the data, json or yaml.
+	DataFormat dataFormat `envconfig:"DATA_FORMAT" default:"json"`
+
+	// Environment variable containing whether the data is always wrapped in a message, even when it is JSON.
+	RawData bool `envconfig:"RAW_DATA" default:"false"`
+
+	// Environment variable containing a comma-separated list of the runtime fields, time and sequence, to add to JSON object data.
+	MessageFields messageFields `envconfig:"MESSAGE_FIELDS"`
+
+	// Environment variable containing the name of the field of the data wrapped in a message.
+	MessageWrapField string `envconfig:"MESSAGE_WRAP_FIELD" default:"body"`
+
+	// Environment variable indicating whether to add the name, namespace and time fields to the data wrapped in a message.
+	MessageWrapMeta bool `envconfig:"MESSAGE_WRAP_META" default:"false"`
+
+	// Environment variable containing how the event ids are generated, uuid or sequence.
+	IDStrategy idStrategy `envconfig:"ID_STRATEGY" default:"uuid"`
+
+	// Environment variable containing the prefix of the uuid event ids, such as a deployment identifier.
+	IDPrefix string `envconfig:"ID_PREFIX"`
+
+	// Environment variables containing the paths of the client certificate and key presented to the sink.
+	TLSClientCert string `envconfig:"TLS_CLIENT_CERT"`
+	TLSClientKey  string `envconfig:"TLS_CLIENT_KEY"`
+
+	// Environment variable containing the path of the CA certificates the sink certificate is verified with.
+	TLSCACert string `envconfig:"TLS_CA_CERT"`
+
+	// Environment variable containing the path of the bearer token file authenticating to the sink.
+	AuthTokenFile string `envconfig:"AUTH_TOKEN_FILE"`
+
+	// Environment variable containing how often the bearer token file is read again.
+	AuthTokenRefresh time.Duration `envconfig:"AUTH_TOKEN_REFRESH" default:"1m"`
+
+	// Environment variable indicating whether to set the event id as the Idempotency-Key header of the requests.
+	IdempotencyKey bool `envconfig:"IDEMPOTENCY_KEY" default:"false"`
+
+	// Environment variable containing the User-Agent header of the requests, knative-pingsource/<version> by default.
+	UserAgent string `envconfig:"USER_AGENT"`
+
+	// Environment variable containing the secret the request bodies are signed with in the X-Ping-Signature header.
+	HMACSecret string `envconfig:"HMAC_SECRET"`
+
+	// Environment variable containing newline or comma-separated Key:Value HTTP headers to set on the requests to the sink.
+	Headers headers `envconfig:"HEADERS"`
+
+	// Environment variable indicating whether to replay on startup the ticks missed while the adapter was down.
+	Catchup bool `envconfig:"CATCHUP" default:"false"`
+
+	// Environment variable containing the path of the file persisting the time of the last tick.
+	CatchupStateFile string `envconfig:"CATCHUP_STATE_FILE"`
+
+	// Environment variable containing the RFC3339 time of the last tick, when there is no state file.
+	CatchupFrom time.Time `envconfig:"CATCHUP_FROM"`
+
+	// Environment variable containing the maximum number of missed ticks replayed.
+	CatchupLimit int `envconfig:"CATCHUP_LIMIT" default:"10"`
+
+	// Environment variable indicating whether to log the events instead of sending them.
+	DryRun bool `envconfig:"DRY_RUN" default:"false"`
+
+	// Environment variable containing the number of events sent after which the adapter completes.
+	MaxEvents int32 `envconfig:"MAX_EVENTS"`
+
+	// Environment variable indicating whether to log every sent event.
+	LogEvents bool `envconfig:"LOG_EVENTS" default:"false"`
+
+	// Environment variable indicating whether the sink replies with an event.
+	ExpectReply bool `envconfig:"EXPECT_REPLY" default:"false"`
+
+	// Environment variable containing the URI the reply events are forwarded to.
+	ReplySink string `envconfig:"REPLY_SINK"`
+
+	// Environment variable indicating whether to send an event when the adapter starts and stops.
+	LifecycleEvents bool `envconfig:"LIFECYCLE_EVENTS" default:"false"`
+
+	// Environment variable containing the type of the event sent last on shutdown, once the in-flight sends are drained.
+	FinalEventType string `envconfig:"FINAL_EVENT_TYPE"`
+
+	// Environment variable containing the size in bytes of the random data sent instead of DATA.
+	RandomDataSize int `envconfig:"RANDOM_DATA_SIZE"`
+
+	// Environment variable containing the size in bytes of the largest payload sent.
+	MaxPayloadBytes int `envconfig:"MAX_PAYLOAD_BYTES"`
+
+	// Environment variable containing what is done with the payloads larger than MAX_PAYLOAD_BYTES, fail, skip or truncate.
+	MaxPayloadAction payloadAction `envconfig:"MAX_PAYLOAD_ACTION" default:"fail"`
+
+	// Environment variable containing the most events sent per second, across ticks, replays, manual fires and batches.
+	RateLimit float64 `envconfig:"RATE_LIMIT"`
+
+	// Environment variable containing the most events sent at once within RATE_LIMIT.
+	RateBurst int `envconfig:"RATE_BURST" default:"1"`
+
+	// Environment variable containing a JSON array of {data, weight} objects, one picked by weight on every tick.
+	DataVariants dataVariants `envconfig:"DATA_VARIANTS"`
+
+	// Environment variable containing the CloudEvents spec version of the events, 1.0 or 0.3.
+	SpecVersion specVersion `envconfig:"CE_SPEC_VERSION" default:"1.0"`
+
+	// Environment variable containing the name of the Service the events are sent to when K_SINK is empty.
+	SinkServiceName string `envconfig:"SINK_SERVICE_NAME"`
+
+	// Environment variable containing the namespace of the sink Service. Defaults to the adapter namespace.
+	SinkServiceNamespace string `envconfig:"SINK_SERVICE_NAMESPACE"`
+
+	// Environment variable containing the path of the sink Service URI.
+	SinkServicePath string `envconfig:"SINK_SERVICE_PATH"`
+
+	// Environment variable containing the number of consecutive failed sends opening the circuit breaker.
+	CircuitBreakerThreshold int `envconfig:"CIRCUIT_BREAKER_THRESHOLD"`
+
+	// Environment variable containing the duration the circuit breaker stays open before a probe tick.
+	CircuitBreakerCooldown time.Duration `envconfig:"CIRCUIT_BREAKER_COOLDOWN" default:"30s"`
+
+	// Environment variable containing how the ticks are timed, on the cron schedule or after random intervals.
+	IntervalMode intervalMode `envconfig:"INTERVAL_MODE" default:"cron"`
+
+	// Environment variable containing the maximum random interval between two ticks.
+	MaxInterval time.Duration `envconfig:"MAX_INTERVAL"`
+
+	// Environment variable indicating whether the probes server serves POST /fire, sending an event immediately.
+	AllowManualFire bool `envconfig:"ALLOW_MANUAL_FIRE" default:"false"`
+
+	// Environment variable containing a JSON object of the data sent instead of DATA, by namespace.
+	DataByNamespace dataByNamespace `envconfig:"DATA_BY_NAMESPACE"`
+
+	// Environment variable containing the Go layout times are rendered with in templates and extensions.
+	TimeFormat string `envconfig:"TIME_FORMAT" default:"2006-01-02T15:04:05Z07:00"`
+
+	// Environment variable indicating whether times are rendered in UTC rather than in the schedule time zone.
+	TimeUTC bool `envconfig:"TIME_UTC" default:"true"`
+
+	// Environment variable containing what the time attribute of the events is: now or scheduled.
+	TimeSource timeSource `envconfig:"TIME_SOURCE" default:"now"`
+
+	// Environment variable containing the name of the payload provider of the data: env, file or a registered one.
+	PayloadProvider string `envconfig:"PAYLOAD_PROVIDER"`
+
+	// Environment variable indicating whether the events carry the adapter version in the pingversion extension.
+	EmitVersion bool `envconfig:"EMIT_VERSION" default:"false"`
+
+	// Environment variable containing a JSON array of additional {schedule, data, sink} entries.
+	Schedules scheduleEntries `envconfig:"SCHEDULES"`
+
+	// Environment variable containing the path of a file suspending the ticks while it exists.
+	PauseFile string `envconfig:"PAUSE_FILE"`
+
+	// Environment variable containing the path of a JSON or YAML file of environment variables, loaded by
+	// NewEnvConfig. The variables set in the environment take precedence.
+	ConfigFile string `envconfig:"CONFIG_FILE"`
+
+	// Environment variable indicating whether to reload the schedule and data when CONFIG_FILE changes.
+	ConfigWatch bool `envconfig:"CONFIG_WATCH" default:"false"`
+
+	// Environment variable containing how long CONFIG_FILE must stay unchanged before it is reloaded.
+	ConfigWatchDebounce time.Duration `envconfig:"CONFIG_WATCH_DEBOUNCE" default:"2s"`
+
+	// Environment variable containing a comma-separated list of the signals, among SIGINT, SIGQUIT and SIGTERM, triggering a graceful shutdown.
+	// The list is additive: SIGTERM and SIGINT, handled by the adapter framework, always trigger it and cannot be removed.
+	ShutdownSignals shutdownSignals `envconfig:"SHUTDOWN_SIGNALS"`
+
+	// Environment variable containing the delay of a tick after its scheduled time above which a warning is logged.
+	DriftWarnThreshold time.Duration `envconfig:"DRIFT_WARN_THRESHOLD" default:"1s"`
+
+	// Environment variable containing the processing time of the sink above which a warning is logged.
+	LatencyWarnThreshold time.Duration `envconfig:"LATENCY_WARN_THRESHOLD"`
+
+	// Environment variable containing the URL which must return a 2xx response to a GET for the ticks to send.
+	PreconditionURL string `envconfig:"PRECONDITION_URL"`
+
+	// Environment variable containing the maximum duration of the GET of PRECONDITION_URL.
+	PreconditionTimeout time.Duration `envconfig:"PRECONDITION_TIMEOUT" default:"2s"`
+
+	// Environment variable containing how long the result of the GET of PRECONDITION_URL is reused.
+	PreconditionCache time.Duration `envconfig:"PRECONDITION_CACHE" default:"5s"`
+
+	// Environment variable containing a semicolon-separated list of the recurring windows, such as
+	// "Mon-Fri 00:00-02:00", the ticks skip sending in.
+	Blackout blackoutWindows `envconfig:"BLACKOUT"`
+
+	// Environment variable containing the recurring window, such as "Mon-Fri 09:00-17:00 America/Chicago",
+	// the ticks only send in.
+	BusinessHours businessHours `envconfig:"BUSINESS_HOURS"`
+
+	// configFileErr is the error loading the CONFIG_FILE, reported by
+	// Validate.
+	configFileErr error
+}
+
+// dataByNamespace is the data of namespaces, decoded from a JSON object.
+// A JSON string is unquoted, any other JSON value is kept as is.
+type dataByNamespace map[string]string
+
+// Decode implements envconfig.Decoder
+func (d *dataByNamespace) Decode(value string) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return fmt.Errorf("invalid data by namespace: must be a JSON object: %v", err)
+	}
+	m := make(dataByNamespace, len(raw))
+	for namespace, data := range raw {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			s = string(data)
+		}
+		m[namespace] = s
+	}
+	*d = m
+	return nil
+}
+
+// data returns the DATA_BY_NAMESPACE entry of the adapter namespace,
+// falling back to DATA.
+func (e *envConfig) data() string {
+	if data, ok := e.DataByNamespace[e.Namespace]; ok {
+		return data
+	}
+	return e.Data
+}
+
+// specVersion is the CloudEvents spec version of the sent events.
+type specVersion string
+
+// Decode implements envconfig.Decoder
+func (v *specVersion) Decode(value string) error {
+	switch value {
+	case cloudevents.VersionV1, cloudevents.VersionV03:
+		*v = specVersion(value)
+		return nil
+	default:
+		return fmt.Errorf("invalid CloudEvents spec version %q: must be %s or %s", value, cloudevents.VersionV1, cloudevents.VersionV03)
+	}
+}
+
+// sinkSpecVersions are the spec versions of the events sent to each of
+// the sinks. An empty version is the one of the adapter.
+type sinkSpecVersions []specVersion
+
+// Decode implements envconfig.Decoder
+func (s *sinkSpecVersions) Decode(value string) error {
+	var versions sinkSpecVersions
+	for _, v := range strings.Split(value, ",") {
+		var version specVersion
+		if v = strings.TrimSpace(v); v != "" {
+			if err := version.Decode(v); err != nil {
+				return err
+			}
+		}
+		versions = append(versions, version)
+	}
+	*s = versions
+	return nil
+}
+
+// idStrategy is how the event ids are generated.
+type idStrategy string
+
+const (
+	idStrategyUUID     idStrategy = "uuid"
+	idStrategySequence idStrategy = "sequence"
+)
+
+// Decode implements envconfig.Decoder
+func (s *idStrategy) Decode(value string) error {
+	switch strategy := idStrategy(value); strategy {
+	case idStrategyUUID, idStrategySequence:
+		*s = strategy
+		return nil
+	default:
+		return fmt.Errorf("invalid id strategy %q: must be %s or %s", value, idStrategyUUID, idStrategySequence)
+	}
+}
+
+// messageFields are the names of the runtime fields added to the data.
+type messageFields []string
+
+const (
+	messageFieldTime     = "time"
+	messageFieldSequence = "sequence"
+)
+
+// Decode implements envconfig.Decoder
+func (f *messageFields) Decode(value string) error {
+	var fields messageFields
+	for _, field := range strings.Split(value, ",") {
+		switch field = strings.TrimSpace(field); field {
+		case "":
+		case messageFieldTime, messageFieldSequence:
+			fields = append(fields, field)
+		default:
+			return fmt.Errorf("invalid message field %q: must be %s or %s", field, messageFieldTime, messageFieldSequence)
+		}
+	}
+	*f = fields
+	return nil
+}
+
+// dataFormat is the format the data is authored in.
+type dataFormat string
+
+const (
+	dataFormatJSON dataFormat = "json"
+	dataFormatYAML dataFormat = "yaml"
+)
+
+// Decode implements envconfig.Decoder
+func (f *dataFormat) Decode(value string) error {
+	switch format := dataFormat(value); format {
+	case dataFormatJSON, dataFormatYAML:
+		*f = format
+		return nil
+	default:
+		return fmt.Errorf("invalid data format %q: must be %s or %s", value, dataFormatJSON, dataFormatYAML)
+	}
+}
+
+// encoding is the HTTP encoding of the sent events. Empty uses the SDK
+// default.
+type encoding string
+
+const (
+	encodingBinary     encoding = "binary"
+	encodingStructured encoding = "structured"
+)
+
+// Decode implements envconfig.Decoder
+func (e *encoding) Decode(value string) error {
+	switch enc := encoding(value); enc {
+	case "", encodingBinary, encodingStructured:
+		*e = enc
+		return nil
+	default:
+		return fmt.Errorf("invalid encoding %q: must be %s or %s", value, encodingBinary, encodingStructured)
+	}
+}
+
+// extensions are CloudEvent extensions decoded from a JSON object of strings.
+type extensions map[string]string
+
+// reservedAttributes are the CloudEvent context attributes and data
+// members which cannot be used as extension names.
+var reservedAttributes = sets.NewString(
+	"id", "source", "specversion", "type", "datacontenttype", "dataschema",
+	"subject", "time", "data", "data_base64",
+)
+
+// adapterExtensions are the extensions set by the adapter, which the
+// EXTENSIONS cannot override.
+var adapterExtensions = sets.NewString(
+	sequenceExtension, partitionKeyExtension, errorDestExtension, errorCodeExtension,
+	replayExtension, correlationIDExtension, kindExtension, processingTimeExtension,
+	scheduledTimeExtension, firedTimeExtension, versionExtension, "traceparent", "tracestate",
+)
+
+// Decode implements envconfig.Decoder
+func (e *extensions) Decode(value string) error {
+	var m map[string]string
+	if err := json.Unmarshal([]byte(value), &m); err != nil {
+		return fmt.Errorf("invalid extensions: %v", err)
+	}
+	for name := range m {
+		if !isValidExtensionName(name) {
+			return fmt.Errorf("invalid extension name %q: must be lowercase alphanumeric", name)
+		}
+		if reservedAttributes.Has(name) {
+			return fmt.Errorf("invalid extension name %q: reserved attribute", name)
+		}
+		if adapterExtensions.Has(name) {
+			return fmt.Errorf("invalid extension name %q: set by the adapter", name)
+		}
+	}
+	*e = m
+	return nil
+}
+
+func isValidExtensionName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// pingAdapter implements the PingSource adapter to trigger a Sink.
+type pingAdapter struct {
+	// Schedule is a cron format string such as 0 * * * * or @hourly
+	Schedule string
+
+	// Data is the data to be posted to the target.
+	Data string
+
+	// Schedules are additional schedules, each sending its own data to
+	// its own sink, or to the adapter sink. The same schedule sending to
+	// the same sink twice is rejected.
+	Schedules []scheduleEntry
+
+	// Timezone is the IANA time zone name such as America/New_York which
+	// the schedule is evaluated in. Defaults to the local time zone.
+	Timezone string
+
+	// TimeFormat is the Go layout the time of a tick is rendered with by
+	// {{.Time}} in templates and in the time extensions. Defaults to
+	// RFC3339.
+	TimeFormat string
+
+	// TimeLocal renders times in the schedule time zone instead of UTC.
+	TimeLocal bool
+
+	// TimeSource is what the time attribute of the events is, the time
+	// they are sent at by default. Replayed ticks always carry their
+	// scheduled time.
+	TimeSource timeSource
+
+	// EventType overrides the type of the sent events.
+	// Defaults to dev.knative.sources.ping.
+	EventType string
+
+	// Source overrides the source of the sent events. Must be a URI-reference.
+	// Defaults to the PingSource resource path.
+	Source string
+
+	// JitterMax is the upper bound of a random delay, recomputed on every
+	// tick, waited before sending the event. It spreads out the load of
+	// PingSources sharing the same schedule. The delay never exceeds the
+	// time left until the next tick. Zero disables jitter.
+	JitterMax time.Duration
+
+	// RunOnce sends a single event, ignoring the schedule, and returns.
+	RunOnce bool
+
+	// EndTime is the time after which the adapter stops sending events
+	// and returns. Zero means no end time.
+	EndTime time.Time
+
+	// StartTime is the time before which the schedule is not started.
+	// Zero means start immediately.
+	StartTime time.Time
+
+	// StartupDelay is waited on every start of the adapter, after the
+	// StartTime, so that a restarting adapter does not fire right away.
+	StartupDelay time.Duration
+
+	// PauseFile is the path of a file which, while it exists, makes the
+	// ticks skip sending. It is checked on every tick.
+	PauseFile string
+
+	// PreconditionURL, when set, is probed with a GET before the ticks
+	// send. The ticks skip sending unless it returns a 2xx response within
+	// the PreconditionTimeout. The result is reused for PreconditionCache.
+	PreconditionURL     string
+	PreconditionTimeout time.Duration
+	PreconditionCache   time.Duration
+
+	// Blackout are the recurring windows of the day, in the time zone of
+	// the schedule, the ticks skip sending in.
+	Blackout blackoutWindows
+
+	// BusinessHours is the recurring window of the day, in its time zone
+	// or the one of the schedule, the ticks only send in. The ticks send
+	// at any time when unset.
+	BusinessHours businessHours
+
+	// DriftWarnThreshold is the delay of a tick after its scheduled time
+	// above which a warning is logged. Zero disables the warning, the
+	// drift is reported in the schedule_drift_seconds metric regardless.
+	DriftWarnThreshold time.Duration
+
+	// LatencyWarnThreshold is the processing time of the sink above which
+	// a warning is logged. The events are then sent as requests, and the
+	// processing time is the processingtime extension of the reply, or the
+	// round-trip time without. Zero disables the warning.
+	LatencyWarnThreshold time.Duration
+
+	// ConfigFile is the path of the file of environment variables loaded
+	// at startup, and again on SIGHUP. With ConfigWatch, the schedule and
+	// data are also reloaded when its content changes and then stays the
+	// same for the ConfigWatchDebounce.
+	ConfigFile          string
+	ConfigWatch         bool
+	ConfigWatchDebounce time.Duration
+
+	// ShutdownSignals trigger a graceful shutdown, like the stop channel
+	// of start, which the adapter framework closes on SIGTERM and SIGINT.
+	// They are in addition to those, which cannot be disabled.
+	ShutdownSignals []os.Signal
+
+	// SkipIfRunning skips a tick when the previous one is still sending,
+	// instead of running both concurrently.
+	SkipIfRunning bool
+
+	// QueueSize queues up to that many ticks of the cron for a single
+	// sender, decoupling the schedule from the sink latency. Zero sends
+	// each tick as it fires. With a queue, SkipIfRunning only keeps the
+	// queued ticks and the manual fires from overlapping.
+	QueueSize int
+
+	// QueueFullPolicy is which tick is dropped when the queue is full.
+	// Defaults to the oldest.
+	QueueFullPolicy queueFullPolicy
+
+	// RetryCount is the maximum number of retries of a failed send.
+	// Zero disables retries.
+	RetryCount int
+
+	// RetryBackoff is the period of the exponential backoff between retries.
+	RetryBackoff time.Duration
+
+	// RetryableStatus are the HTTP status codes and classes of the failed
+	// sends which are retried, along with the sends getting no response.
+	// Empty retries on 404, 425, 429, 503 and 504, as the client does.
+	RetryableStatus retryableStatus
+
+	// SendTimeout bounds the duration of a send, retries included, so that
+	// an unresponsive sink does not block the ticks. Zero means no timeout.
+	SendTimeout time.Duration
+
+	// DataFromFile is the path of a file containing the data, such as a
+	// mounted ConfigMap or Secret. Mutually exclusive with Data.
+	DataFromFile string
+
+	// DataReload reads DataFromFile again on every tick to pick up changes.
+	DataReload bool
+
+	// DataStream sends the content of DataFromFile as the data while it
+	// is read, instead of reading it in memory on every tick. The data is
+	// sent as is, in binary encoding.
+	DataStream bool
+
+	// Payload, when set, provides the data of every tick instead of Data
+	// and DataFromFile. The data is sent as is, without templating, unless
+	// it is an EnvPayload, sent like Data.
+	Payload PayloadProvider
+
+	// DataContentType is the content type of the data. When set, the data
+	// is sent as is. Otherwise it is sent as JSON, wrapped in a Message
+	// if it is not a JSON object.
+	DataContentType string
+
+	// DataBase64 indicates the data is base64 encoded binary data. It is
+	// decoded and sent with DataContentType, or application/octet-stream.
+	DataBase64 bool
+
+	// Subject is the subject of the sent events. The {namespace} and {name}
+	// placeholders are replaced with the adapter namespace and name. The
+	// subject is not set when empty.
+	Subject string
+
+	// PartitionKey is the partitionkey extension of the sent events, which
+	// the Kafka binding partitions the events by. The {namespace} and
+	// {name} placeholders are replaced as in the Subject. The extension is
+	// not set when empty.
+	PartitionKey string
+
+	// Extensions are CloudEvent extensions set on the sent events.
+	Extensions map[string]string
+
+	// DataTemplate indicates the data is a text/template executed on every
+	// tick with templateData.
+	DataTemplate bool
+
+	// ProbePort is the port serving the /healthz and /readyz probes.
+	// Zero disables the probes server.
+	ProbePort int
+
+	// MinInterval is the minimum interval between two ticks of the
+	// schedule, protecting sinks from too frequent schedules. Zero
+	// disables the check. It is also the minimum random interval.
+	MinInterval time.Duration
+
+	// IntervalMode is how the ticks are timed. The random mode ignores
+	// the schedule, ticking after random intervals between MinInterval
+	// and MaxInterval. Defaults to the cron schedule.
+	IntervalMode intervalMode
+
+	// MaxInterval is the maximum random interval between two ticks.
+	MaxInterval time.Duration
+
+	// AllowManualFire serves POST /fire on the probes server, sending the
+	// event of an immediate tick and returning its id.
+	AllowManualFire bool
+
+	// EmitVersion sets the pingversion extension of the events to the
+	// version the adapter was built with.
+	EmitVersion bool
+
+	// DrainTimeout is the maximum duration to wait on shutdown for the
+	// in-flight sends to complete. Zero does not wait.
+	DrainTimeout time.Duration
+
+	// Sinks are the URIs each event is sent to instead of the adapter sink.
+	Sinks []string
+
+	// SinkSpecVersions are the CloudEvents spec versions of the events sent
+	// to each of Sinks, by index. Empty or missing ones are SpecVersion.
+	SinkSpecVersions []specVersion
+
+	// Sink is the URI of the adapter sink.
+	Sink string
+
+	// DeadLetterSink is the URI receiving the events which could not be
+	// sent after all retries, with the knativeerrordest and
+	// knativeerrorcode extensions describing the failure.
+	DeadLetterSink string
+
+	// Encoding forces the binary or structured HTTP encoding of the sent
+	// events. Empty uses the SDK default.
+	Encoding encoding
+
+	// FallbackEncoding is the encoding the events are sent with once more
+	// when the sink rejects their encoding with a 415 or 406 response.
+	// Empty disables the fallback.
+	FallbackEncoding encoding
+
+	// Batch sends one event per element of the data, a JSON array, on
+	// every tick. The sequence extension increments across the batch. The
+	// array is split after rendering the data template, with the sequence
+	// of the first event. An element which is an object of only data and
+	// contentType members is sent as its data, with that content type.
+	Batch bool
+
+	// SecondsField indicates the schedule has 6 fields, the first one
+	// being the seconds, such as */15 * * * * *.
+	SecondsField bool
+
+	// DataSchema is the absolute URI of the schema of the data, set as the
+	// dataschema attribute of the sent events. Empty omits the attribute.
+	DataSchema string
+
+	// ValidateSchema is an inline JSON Schema, or the path of a file
+	// containing one, the payload of the events must conform to. Static
+	// data is validated by start, rendered data templates on every tick,
+	// skipped when not conforming. Only a subset of JSON Schema is
+	// supported, see jsonSchema, the other keywords are rejected.
+	ValidateSchema string
+
+	// DataFormat is the format of the data sent as JSON. YAML data is
+	// converted to JSON, or wrapped in a Message when it is not a YAML
+	// mapping. Defaults to JSON.
+	DataFormat dataFormat
+
+	// RawData always wraps the data in a Message, unchanged, instead of
+	// sending JSON object data as is.
+	RawData bool
+
+	// MessageFields are the runtime fields, time and sequence of the tick,
+	// added to the JSON object data unless already present.
+	MessageFields []string
+
+	// MessageWrapField is the name of the field of the data wrapped in a
+	// message, body when empty.
+	MessageWrapField string
+
+	// MessageWrapMeta adds the name, namespace and time of the tick to
+	// the data wrapped in a message.
+	MessageWrapMeta bool
+
+	// IDStrategy is how the event ids are generated. The sequence strategy
+	// sets reproducible {namespace}-{name}-{sequence} ids, unique until the
+	// adapter restarts. Defaults to the random UUIDs of the client.
+	IDStrategy idStrategy
+
+	// IDPrefix prefixes the UUIDs of the uuid IDStrategy, as
+	// {prefix}-{uuid}. It is made of letters, digits, '.', '_' and '-'.
+	IDPrefix string
+
+	// Catchup replays on startup the ticks missed since the last one,
+	// whose time is persisted in CatchupStateFile or given by
+	// CatchupFrom. Replayed events carry the pingreplay extension.
+	Catchup bool
+
+	// CatchupStateFile is the path of the file the time of the last tick
+	// is written to.
+	CatchupStateFile string
+
+	// CatchupFrom is the time of the last tick when CatchupStateFile
+	// doesn't exist.
+	CatchupFrom time.Time
+
+	// CatchupLimit is the maximum number of replayed ticks. The most
+	// recent ones are replayed.
+	CatchupLimit int
+
+	// DryRun logs the events at info level instead of sending them. Dry
+	// run events are not counted in the metrics.
+	DryRun bool
+
+	// MaxEvents is the number of successfully sent events after which
+	// the cron is stopped and start returns. Zero is unlimited.
+	MaxEvents int32
+
+	// LogEvents logs the id, type, sequence and send latency of every
+	// event at info level.
+	LogEvents bool
+
+	// ExpectReply sends the events as requests, logging the event the
+	// sink replies with.
+	ExpectReply bool
+
+	// ReplySink is the URI the reply events are forwarded to. Empty only
+	// logs them.
+	ReplySink string
+
+	// LifecycleEvents sends a dev.knative.source.ping.started event when
+	// start begins, and a dev.knative.source.ping.stopped event, within
+	// the drain window, on shutdown.
+	LifecycleEvents bool
+
+	// FinalEventType, when set, is the type of an event sent on shutdown
+	// once the in-flight sends are drained, in what is left of the drain
+	// window, signaling that no more events are coming.
+	FinalEventType string
+
+	// RandomDataSize, when positive, is the size in bytes of the random
+	// data generated on every tick and sent instead of Data, as
+	// DataContentType or application/octet-stream. At most
+	// maxRandomDataSize.
+	RandomDataSize int
+
+	// MaxPayloadBytes, when positive, is the size in bytes of the largest
+	// event data sent. The larger payloads are handled according to the
+	// MaxPayloadAction.
+	MaxPayloadBytes int
+
+	// MaxPayloadAction is what is done with the payloads larger than
+	// MaxPayloadBytes: fail at start for static data, or fail the tick
+	// otherwise, skip the event, or truncate the payload. Truncated JSON
+	// is sent as text/plain.
+	MaxPayloadAction payloadAction
+
+	// RateLimit, when positive, is the most events sent per second, with
+	// bursts of RateBurst events, replayed ticks included. The events which
+	// would wait beyond the next tick are dropped. The lifecycle events are
+	// not limited.
+	RateLimit float64
+	RateBurst int
+
+	// DataVariants are the payloads sent instead of Data, one picked at
+	// random on every tick with a probability proportional to its weight.
+	DataVariants []dataVariant
+
+	// SpecVersion is the CloudEvents spec version of the sent events.
+	// Defaults to 1.0.
+	SpecVersion specVersion
+
+	// CircuitBreakerThreshold, when positive, is the number of consecutive
+	// failed sends after which the ticks are skipped for
+	// CircuitBreakerCooldown. The next tick then probes the sink, closing
+	// the circuit on success. Zero disables the circuit breaker.
+	CircuitBreakerThreshold int
+
+	// CircuitBreakerCooldown is the duration the circuit breaker stays
+	// open.
+	CircuitBreakerCooldown time.Duration
+
+	// Name is the name of the adapter.
+	Name string
+
+	// Namespace is the namespace of the adapter.
+	Namespace string
+
+	// client sends cloudevents.
+	Client cloudevents.Client
+
+	// clock tells the time. Defaults to the real clock.
+	clock Clock
+
+	// mu guards the fields below.
+	mu sync.Mutex
+
+	// schedule is the parsed Schedule.
+	schedule cron.Schedule
+
+	// sequence is the number of events sent since the process started.
+	sequence int32
+
+	// sent is the number of events successfully sent.
+	sent int32
+	// reserved is the number of events sent or being sent, up to the
+	// MaxEvents.
+	reserved int32
+
+	// stop terminates a running start.
+	stop context.CancelFunc
+
+	// ctx is the context of a running start, carrying the logger and
+	// cancelled on shutdown.
+	ctx context.Context
+
+	// running is 1 while a tick guarded by skipIfRunning is in progress.
+	running int32
+
+	// queue holds the ticks waiting for runQueue, with a QueueSize.
+	queue *sendQueue
+
+	// fileData is the last content read from DataFromFile.
+	fileData string
+
+	// precondition is the last probe of the PreconditionURL.
+	precondition precondition
+
+	// limiter limits the rate of the sends, with RateLimit.
+	limiter *tokenBucket
+
+	// logger is the logger of the context the adapter is created with.
+	logger *zap.SugaredLogger
+
+	// stream sends the events whose data is streamed from DataFromFile.
+	stream protocol.Sender
+	// openStream opens the streamed DataFromFile, os.Open when nil.
+	openStream func(path string) (io.ReadCloser, error)
+
+	// tmpl is the parsed data template of tmplText.
+	tmpl     *template.Template
+	tmplText string
+
+	// ready is true while the cron is running.
+	ready bool
+
+	// lastTick is the time of the last tick, or of the cron start.
+	lastTick time.Time
+
+	// lastSuccess and lastFailure are the times of the last successful and
+	// failed sends.
+	lastSuccess time.Time
+	lastFailure time.Time
+
+	// schema is the parsed ValidateSchema.
+	schema *jsonSchema
+
+	// entries are the parsed schedules of the Schedules.
+	entries []cron.Schedule
+
+	// inflight tracks the ticks in progress, counted by inflightCount.
+	inflight      sync.WaitGroup
+	inflightCount int32
+
+	// slots records the activation times of the cron.
+	slots slots
+
+	// breaker skips the ticks while the sink is failing.
+	breaker circuitBreaker
+
+	// rnd picks the data variants. Defaults to the global source.
+	rnd *rand.Rand
+
+	// cron runs the schedule. reloadSignals, when set, replaces the SIGHUP
+	// notifications triggering a reload.
+	cron          *cron.Cron
+	reloadSignals chan os.Signal
+	// configChanges, when set, replaces the watch of the ConfigFile.
+	configChanges chan struct{}
+}
+
+func init() {
+	_ = os.Setenv("K_RESOURCE_GROUP", "pingsources.sources.knative.dev")
+	rand.Seed(time.Now().UnixNano())
+}
+
+// NewEnvConfig returns the envConfig processed from the environment. The
+// variables of the CONFIG_FILE, when set, are loaded into the environment
+// first, without overriding those already set. An error loading it is
+// returned by Validate.
+func NewEnvConfig() adapter.EnvConfigAccessor {
+	env := &envConfig{}
+	if path := os.Getenv(configFileEnv); path != "" {
+		env.configFileErr = loadConfigFile(path)
+	}
+	return env
+}
+
+// Validate implements adapter.EnvConfigValidator
+func (e *envConfig) Validate(ctx context.Context) error {
+	if e.configFileErr != nil {
+		return e.configFileErr
+	}
+	if len(e.DataByNamespace) > 0 && e.data() == "" {
+		return fmt.Errorf("no DATA_BY_NAMESPACE entry for namespace %q and no DATA", e.Namespace)
+	}
+	// The PingSources without jsonData set an empty DATA, sent as is.
+	if _, ok := os.LookupEnv("DATA"); !ok && e.PayloadProvider == "" && e.data() == "" && e.DataFromFile == "" &&
+		e.RandomDataSize == 0 && len(e.DataVariants) == 0 {
+		return errors.New("one of DATA, DATA_FROM_FILE, DATA_VARIANTS or RANDOM_DATA_SIZE must be set")
+	}
+	if err := e.validatePayloadProvider(); err != nil {
+		return err
+	}
+	if err := e.validateSink(); err != nil {
+		return err
+	}
+	if e.DataStream && e.Compression == compressionGzip {
+		// The gzip transport compresses the whole body in memory.
+		return errors.New("DATA_STREAM is mutually exclusive with COMPRESSION")
+	}
+	if e.DataStream && e.HMACSecret != "" {
+		// The signature transport reads the whole body in memory to sign it.
+		return errors.New("DATA_STREAM is mutually exclusive with HMAC_SECRET")
+	}
+	if sink := e.GetSink(); strings.HasPrefix(sink, fileScheme) {
+		if _, err := filePath(sink); err != nil {
+			return err
+		}
+	}
+	if _, err := e.wrapRoundTripper(baseTransport()); err != nil {
+		return err
+	}
+	_, _, err := newPingAdapter(e, nil).validate(ctx)
+	return err
+}
+
+func NewAdapter(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
+	env := processed.(*envConfig)
+
+	registerViews()
+
+	if sink := env.GetSink(); strings.HasPrefix(sink, fileScheme) {
+		fc, err := newFileClient(sink, ceClient)
+		if err != nil {
+			logging.FromContext(ctx).Fatalw("Error creating the file sink client", zap.Error(err))
+		}
+		ceClient = fc
+	}
+	a := newPingAdapter(env, ceClient)
+	a.logger = logging.FromContext(ctx)
+
+	payload, err := env.payloadProvider(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Fatalw("Error creating the payload provider", zap.Error(err))
+	}
+	a.Payload = payload
+
+	if env.DataStream && !strings.HasPrefix(env.GetSink(), fileScheme) {
+		// The client does not expose its protocol, the streamed events are
+		// sent with one of their own over the same transport, set on an
+		// http.Client of its own rather than the shared default one.
+		rt, err := env.wrapRoundTripper(baseTransport())
+		if err != nil {
+			logging.FromContext(ctx).Fatalw("Error creating the data stream transport", zap.Error(err))
+		}
+		stream, err := cehttp.New(cehttp.WithTarget(env.GetSink()), cehttp.WithClient(http.Client{}), cehttp.WithRoundTripper(rt))
+		if err != nil {
+			logging.FromContext(ctx).Fatalw("Error creating the data stream protocol", zap.Error(err))
+		}
+		a.stream = stream
+	}
+	return a
+}
+
+func newPingAdapter(env *envConfig, ceClient cloudevents.Client) *pingAdapter {
+	return &pingAdapter{
+		Schedule:                env.Schedule,
+		Data:                    env.data(),
+		Schedules:               env.Schedules,
+		Timezone:                env.Timezone,
+		TimeFormat:              env.TimeFormat,
+		TimeLocal:               !env.TimeUTC,
+		TimeSource:              env.TimeSource,
+		EventType:               env.EventType,
+		Source:                  env.EventSource,
+		JitterMax:               env.JitterMax,
+		RunOnce:                 env.RunOnce,
+		EndTime:                 env.EndTime,
+		StartTime:               env.StartTime,
+		StartupDelay:            env.StartupDelay,
+		PauseFile:               env.PauseFile,
+		Blackout:                env.Blackout,
+		BusinessHours:           env.BusinessHours,
+		PreconditionURL:         env.PreconditionURL,
+		PreconditionTimeout:     env.PreconditionTimeout,
+		PreconditionCache:       env.PreconditionCache,
+		DriftWarnThreshold:      env.DriftWarnThreshold,
+		LatencyWarnThreshold:    env.LatencyWarnThreshold,
+		ConfigFile:              env.ConfigFile,
+		ConfigWatch:             env.ConfigWatch,
+		ConfigWatchDebounce:     env.ConfigWatchDebounce,
+		ShutdownSignals:         env.ShutdownSignals,
+		SkipIfRunning:           env.SkipIfRunning,
+		QueueSize:               env.QueueSize,
+		QueueFullPolicy:         env.QueueFullPolicy,
+		RetryCount:              env.RetryCount,
+		RetryBackoff:            env.RetryBackoff,
+		RetryableStatus:         env.RetryableStatus,
+		SendTimeout:             env.SendTimeout,
+		DataFromFile:            env.DataFromFile,
+		DataReload:              env.DataReload,
+		DataStream:              env.DataStream,
+		DataContentType:         env.DataContentType,
+		DataBase64:              env.DataBase64,
+		Subject:                 env.Subject,
+		PartitionKey:            env.PartitionKey,
+		Extensions:              env.Extensions,
+		DataTemplate:            env.DataTemplate,
+		ProbePort:               env.ProbePort,
+		MinInterval:             env.MinInterval,
+		IntervalMode:            env.IntervalMode,
+		MaxInterval:             env.MaxInterval,
+		AllowManualFire:         env.AllowManualFire,
+		EmitVersion:             env.EmitVersion,
+		DrainTimeout:            env.DrainTimeout,
+		Sinks:                   env.Sinks,
+		SinkSpecVersions:        env.SinkSpecVersions,
+		Sink:                    env.GetSink(),
+		DeadLetterSink:          env.DeadLetterSink,
+		Encoding:                env.Encoding,
+		FallbackEncoding:        env.FallbackEncoding,
+		Batch:                   env.Batch,
+		SecondsField:            env.SecondsField,
+		DataSchema:              env.DataSchema,
+		ValidateSchema:          env.ValidateSchema,
+		DataFormat:              env.DataFormat,
+		RawData:                 env.RawData,
+		MessageFields:           env.MessageFields,
+		MessageWrapField:        env.MessageWrapField,
+		MessageWrapMeta:         env.MessageWrapMeta,
+		IDStrategy:              env.IDStrategy,
+		IDPrefix:                env.IDPrefix,
+		Catchup:                 env.Catchup,
+		CatchupStateFile:        env.CatchupStateFile,
+		CatchupFrom:             env.CatchupFrom,
+		CatchupLimit:            env.CatchupLimit,
+		DryRun:                  env.DryRun,
+		MaxEvents:               env.MaxEvents,
+		LogEvents:               env.LogEvents,
+		ExpectReply:             env.ExpectReply,
+		ReplySink:               env.ReplySink,
+		LifecycleEvents:         env.LifecycleEvents,
+		FinalEventType:          env.FinalEventType,
+		RandomDataSize:          env.RandomDataSize,
+		MaxPayloadBytes:         env.MaxPayloadBytes,
+		MaxPayloadAction:        env.MaxPayloadAction,
+		RateLimit:               env.RateLimit,
+		RateBurst:               env.RateBurst,
+		DataVariants:            env.DataVariants,
+		SpecVersion:             env.SpecVersion,
+		CircuitBreakerThreshold: env.CircuitBreakerThreshold,
+		CircuitBreakerCooldown:  env.CircuitBreakerCooldown,
+		Name:                    env.Name,
+		Namespace:               env.Namespace,
+		Client:                  ceClient,
+	}
+}
+
+func (a *pingAdapter) Start(ctx context.Context) error {
+	return a.start(ctx.Done())
+}
+
+// prepare validates the configuration and loads the data, returning the
+// parsed schedule and the options of its cron.
+func (a *pingAdapter) prepare(ctx context.Context) (cron.Schedule, []cron.Option, error) {
+	sched, opts, err := a.validate(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := a.initialize(ctx); err != nil {
+		return nil, nil, err
+	}
+	return sched, opts, nil
+}
+
+// validate checks the configuration, without reading the files it refers
+// to, returning the parsed schedule and the options of its cron.
+func (a *pingAdapter) validate(ctx context.Context) (cron.Schedule, []cron.Option, error) {
+	var sched cron.Schedule
+	if a.IntervalMode == intervalModeRandom {
+		if err := a.validateIntervals(); err != nil {
+			return nil, nil, err
+		}
+		if a.Catchup {
+			return nil, nil, errors.New("CATCHUP requires the cron interval mode")
+		}
+	} else {
+		var err error
+		if sched, err = a.parseSchedule(a.Schedule); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if _, err := a.prepareEntries(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	if a.EventType != "" && !isValidEventType(a.EventType) {
+		return nil, nil, fmt.Errorf("invalid event type %q", a.EventType)
+	}
+	if a.FinalEventType != "" && !isValidEventType(a.FinalEventType) {
+		return nil, nil, fmt.Errorf("invalid final event type %q", a.FinalEventType)
+	}
+
+	if a.IDPrefix != "" {
+		if a.IDStrategy == idStrategySequence {
+			return nil, nil, errors.New("ID_PREFIX requires the uuid ID_STRATEGY")
+		}
+		if !isValidIDPrefix(a.IDPrefix) {
+			return nil, nil, fmt.Errorf("invalid id prefix %q: must only contain letters, digits, '.', '_' and '-'", a.IDPrefix)
+		}
+	}
+
+	if a.RetryCount < 0 {
+		return nil, nil, fmt.Errorf("invalid retry count %d: must not be negative", a.RetryCount)
+	}
+	if a.RetryCount > 0 && a.RetryBackoff <= 0 {
+		return nil, nil, fmt.Errorf("invalid retry backoff %v: must be positive", a.RetryBackoff)
+	}
+
+	if a.StartupDelay < 0 {
+		return nil, nil, fmt.Errorf("invalid startup delay %v: must not be negative", a.StartupDelay)
+	}
+
+	if a.MaxEvents < 0 {
+		return nil, nil, fmt.Errorf("invalid max events %d: must not be negative", a.MaxEvents)
+	}
+
+	if a.FallbackEncoding != "" && a.FallbackEncoding == a.Encoding {
+		return nil, nil, fmt.Errorf("invalid fallback encoding %s: must differ from the encoding", a.FallbackEncoding)
+	}
+
+	if a.CircuitBreakerThreshold < 0 {
+		return nil, nil, fmt.Errorf("invalid circuit breaker threshold %d: must not be negative", a.CircuitBreakerThreshold)
+	}
+	if a.CircuitBreakerThreshold > 0 && a.CircuitBreakerCooldown <= 0 {
+		return nil, nil, fmt.Errorf("invalid circuit breaker cooldown %v: must be positive", a.CircuitBreakerCooldown)
+	}
+
+	if a.RandomDataSize < 0 || a.RandomDataSize > maxRandomDataSize {
+		return nil, nil, fmt.Errorf("invalid random data size %d: must be between 0 and %d", a.RandomDataSize, maxRandomDataSize)
+	}
+
+	if a.Catchup && a.CatchupLimit < 0 {
+		return nil, nil, fmt.Errorf("invalid catch-up limit %d: must not be negative", a.CatchupLimit)
+	}
+
+	if a.DataFromFile != "" && a.Data != "" {
+		return nil, nil, errors.New("DATA and DATA_FROM_FILE are mutually exclusive")
+	}
+	if a.DataStream {
+		if err := a.validateStream(); err != nil {
+			return nil, nil, err
+		}
+	}
+	// A schema file is only read at start.
+	if isInlineSchema(a.ValidateSchema) {
+		if _, err := loadSchema(a.ValidateSchema); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(a.DataVariants) > 0 && (a.Data != "" || a.DataFromFile != "") {
+		return nil, nil, errors.New("DATA_VARIANTS is mutually exclusive with DATA and DATA_FROM_FILE")
+	}
+	if a.DataFromFile == "" {
+		if err := a.validateAllData(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if a.SendTimeout < 0 {
+		return nil, nil, fmt.Errorf("invalid send timeout %v: must not be negative", a.SendTimeout)
+	}
+
+	for _, sink := range a.Sinks {
+		if u, err := url.Parse(sink); err != nil || !u.IsAbs() {
+			return nil, nil, fmt.Errorf("invalid sink %q: must be an absolute URI", sink)
+		}
+	}
+	if len(a.SinkSpecVersions) > len(a.Sinks) {
+		return nil, nil, fmt.Errorf("invalid sink spec versions: %d versions for %d sinks", len(a.SinkSpecVersions), len(a.Sinks))
+	}
+	if a.DeadLetterSink != "" {
+		if u, err := url.Parse(a.DeadLetterSink); err != nil || !u.IsAbs() {
+			return nil, nil, fmt.Errorf("invalid dead letter sink %q: must be an absolute URI", a.DeadLetterSink)
+		}
+	}
+
+	if a.ReplySink != "" {
+		if !a.ExpectReply {
+			return nil, nil, errors.New("REPLY_SINK requires EXPECT_REPLY")
+		}
+		if u, err := url.Parse(a.ReplySink); err != nil || !u.IsAbs() {
+			return nil, nil, fmt.Errorf("invalid reply sink %q: must be an absolute URI", a.ReplySink)
+		}
+	}
+
+	if a.DataSchema != "" {
+		if u, err := url.Parse(a.DataSchema); err != nil || !u.IsAbs() {
+			return nil, nil, fmt.Errorf("invalid data schema %q: must be an absolute URI", a.DataSchema)
+		}
+	}
+
+	if a.Source != "" {
+		if _, err := url.Parse(a.Source); err != nil {
+			return nil, nil, fmt.Errorf("invalid event source %q: %v", a.Source, err)
+		}
+	}
+
+	if a.QueueSize < 0 {
+		return nil, nil, fmt.Errorf("invalid queue size %d: must not be negative", a.QueueSize)
+	}
+
+	if err := validateTimeFormat(a.TimeFormat); err != nil {
+		return nil, nil, err
+	}
+
+	if a.RateLimit < 0 {
+		return nil, nil, fmt.Errorf("invalid rate limit %v: must not be negative", a.RateLimit)
+	}
+	if a.RateLimit > 0 && a.RateBurst < 0 {
+		return nil, nil, fmt.Errorf("invalid rate burst %d: must not be negative", a.RateBurst)
+	}
+
+	if a.RawData && a.DataFormat == dataFormatYAML {
+		return nil, nil, errors.New("RAW_DATA is mutually exclusive with the yaml DATA_FORMAT")
+	}
+
+	if a.MessageWrapMeta {
+		switch a.MessageWrapField {
+		case "name", "namespace", "time":
+			return nil, nil, fmt.Errorf("invalid message wrap field %q: must not be a field of MESSAGE_WRAP_META", a.MessageWrapField)
+		}
+	}
+
+	if a.PreconditionURL != "" {
+		if u, err := url.Parse(a.PreconditionURL); err != nil || !u.IsAbs() {
+			return nil, nil, fmt.Errorf("invalid precondition URL %q: must be an absolute URI", a.PreconditionURL)
+		}
+	}
+
+	if a.MaxPayloadBytes < 0 {
+		return nil, nil, fmt.Errorf("invalid max payload bytes %d: must not be negative", a.MaxPayloadBytes)
+	}
+	if a.DataFromFile == "" {
+		if err := a.validatePayloadSize(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var opts []cron.Option
+	if a.Timezone != "" {
+		loc, err := time.LoadLocation(a.Timezone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid timezone %s: %v", a.Timezone, err)
+		}
+		opts = append(opts, cron.WithLocation(loc))
+	}
+
+	if !a.EndTime.IsZero() && !a.now().Before(a.EndTime) {
+		return nil, nil, fmt.Errorf("end time %s has already passed", a.EndTime.Format(time.RFC3339))
+	}
+	return sched, opts, nil
+}
 
-	// Environment variable containing data.
-	Data string `envconfig:"DATA" required:"true"`
+// initialize loads the files the validated configuration refers to, and
+// validates the data read from them.
+func (a *pingAdapter) initialize(ctx context.Context) error {
+	if a.DataStream {
+		if _, err := os.Stat(a.DataFromFile); err != nil {
+			return fmt.Errorf("failed to read data file %s: %v", a.DataFromFile, err)
+		}
+	} else if a.DataFromFile != "" {
+		if err := a.loadData(); err != nil {
+			return err
+		}
+	}
+
+	if a.ValidateSchema != "" {
+		schema, err := loadSchema(a.ValidateSchema)
+		if err != nil {
+			return err
+		}
+		a.schema = schema
+	}
+
+	// The data is validated again against the schema.
+	entries, err := a.prepareEntries(ctx)
+	if err != nil {
+		return err
+	}
+	a.entries = entries
+	if a.schema != nil || a.DataFromFile != "" {
+		if err := a.validateAllData(ctx); err != nil {
+			return err
+		}
+	}
+	if a.DataFromFile != "" {
+		if err := a.validatePayloadSize(ctx); err != nil {
+			return err
+		}
+	}
+
+	if a.RateLimit > 0 {
+		a.limiter = newTokenBucket(a.RateLimit, a.RateBurst)
+	}
+	return nil
 }
 
-// pingAdapter implements the PingSource adapter to trigger a Sink.
-type pingAdapter struct {
-	// Schedule is a cron format string such as 0 * * * * or @hourly
-	Schedule string
+// validateAllData validates the DataVariants, or the data.
+func (a *pingAdapter) validateAllData(ctx context.Context) error {
+	if len(a.DataVariants) > 0 {
+		for i, v := range a.DataVariants {
+			if err := a.validateData(ctx, v.Data); err != nil {
+				return fmt.Errorf("invalid data variant %d: %v", i, err)
+			}
+		}
+		return nil
+	}
+	data, _ := a.data()
+	return a.validateData(ctx, data)
+}
 
-	// Data is the data to be posted to the target.
+func (a *pingAdapter) start(stopCh <-chan struct{}) error {
+	// ctx is cancelled on shutdown to interrupt ticks waiting to send.
+	ctx, cancel := context.WithCancel(a.loggerContext())
+	defer cancel()
+
+	sched, opts, err := a.prepare(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.schedule = sched
+	a.stop = cancel
+	a.ctx = ctx
+	a.mu.Unlock()
+
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	defer a.notifyShutdown(ctx, cancel)()
+
+	// shutdownProbes stops the probes server, returning once the ticks
+	// fired with it are done.
+	shutdownProbes := func() {}
+	if a.ProbePort > 0 {
+		srv := &http.Server{
+			Addr:    fmt.Sprintf(":%d", a.ProbePort),
+			Handler: a.probeHandler(),
+		}
+		go func() {
+			// Don't forward ErrServerClosed as that indicates we're already shutting down.
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logging.FromContext(ctx).Errorw("ping probes server failed", zap.Error(err))
+			}
+		}()
+		shutdownProbes = func() { srv.Shutdown(context.Background()) }
+		defer shutdownProbes()
+	}
+
+	if wait := a.StartTime.Sub(a.now()); !a.StartTime.IsZero() && wait > 0 {
+		t := a.getClock().NewTimer(wait)
+		select {
+		case <-t.C():
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		}
+	}
+
+	if a.StartupDelay > 0 {
+		t := a.getClock().NewTimer(a.StartupDelay)
+		select {
+		case <-t.C():
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		}
+	}
+
+	if a.LifecycleEvents {
+		a.sendLifecycleEvent(ctx, startedEventType)
+	}
+
+	if a.RunOnce {
+		err := a.cronTick(ctx)
+		drainStart := a.now()
+		if a.LifecycleEvents {
+			a.sendStoppedEvent(ctx)
+			a.drain(ctx, shutdownProbes)
+		}
+		a.sendFinalEvent(ctx, drainStart)
+		return err
+	}
+
+	if a.IntervalMode == intervalModeRandom {
+		a.setReady(true)
+		a.runRandom(ctx)
+		a.setReady(false)
+		drainStart := a.now()
+		if a.LifecycleEvents {
+			a.sendStoppedEvent(ctx)
+		}
+		a.drain(ctx, shutdownProbes)
+		a.sendFinalEvent(ctx, drainStart)
+		return nil
+	}
+
+	if a.Catchup {
+		a.catchup(ctx, sched)
+	}
+
+	tick := func() context.Context { return withSlot(ctx, a.slots.fired(a.now())) }
+	var job cron.Job = cron.FuncJob(func() { _ = a.cronTick(tick()) })
+	// queueDone is closed once no queued tick can start anymore.
+	queueDone := make(chan struct{})
+	if a.QueueSize > 0 {
+		a.queue = newSendQueue(a.QueueSize, a.QueueFullPolicy)
+		job = a.queued(ctx, tick)
+		go func() {
+			defer close(queueDone)
+			a.runQueue(ctx)
+		}()
+	} else {
+		close(queueDone)
+		if a.SkipIfRunning {
+			job = a.skipIfRunning(ctx, job)
+		}
+	}
+
+	reloadCh := a.reloadSignals
+	if reloadCh == nil {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGHUP)
+		defer signal.Stop(ch)
+		reloadCh = ch
+	}
+	configCh := a.configChanges
+	if configCh == nil && a.ConfigWatch && a.ConfigFile != "" {
+		ch := make(chan struct{}, 1)
+		go a.watchConfigFile(ctx, a.ConfigFile, ch)
+		configCh = ch
+	}
+
+	c := cron.New(opts...)
+	id := c.Schedule(a.slots.track(sched), job)
+	a.scheduleEntries(ctx, c, a.entries)
+	a.mu.Lock()
+	a.cron = c
+	a.mu.Unlock()
+	c.Start()
+	a.setReady(true)
+	for done := false; !done; {
+		select {
+		case <-reloadCh:
+			id = a.reload(ctx, c, id, job)
+		case <-configCh:
+			id = a.reload(ctx, c, id, job)
+		case <-ctx.Done():
+			done = true
+		}
+	}
+	a.setReady(false)
+	jobs := c.Stop()
+	drainStart := a.now()
+	if a.LifecycleEvents {
+		a.sendStoppedEvent(ctx)
+	}
+	a.drain(ctx, func() { <-jobs.Done() }, func() { <-queueDone }, shutdownProbes)
+	a.sendFinalEvent(ctx, drainStart)
+	return nil
+}
+
+// drain waits up to DrainTimeout for the in-flight ticks to complete. The
+// stopped funcs are called first, each returning once the ticks it may
+// start are started, so that no tick starts while waiting for them.
+func (a *pingAdapter) drain(ctx context.Context, stopped ...func()) {
+	if a.DrainTimeout <= 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for _, stop := range stopped {
+			stop()
+		}
+		a.inflight.Wait()
+		close(done)
+	}()
+
+	t := a.getClock().NewTimer(a.DrainTimeout)
+	defer t.Stop()
+	select {
+	case <-done:
+	case <-t.C():
+		logging.FromContext(ctx).Warnw("ping drain timeout expired, abandoning in-flight sends",
+			zap.Int32("abandoned", atomic.LoadInt32(&a.inflightCount)))
+	}
+}
+
+// intervalSamples is the number of ticks sampled to compute the interval of
+// a cron expression.
+const intervalSamples = 64
+
+// scheduleInterval returns the shortest interval between two ticks of
+// sched parsed from spec, sampling the ticks following now.
+func scheduleInterval(spec string, sched cron.Schedule, now time.Time) time.Duration {
+	// cron rounds @every durations up to one second, use the requested one.
+	if strings.HasPrefix(spec, "@every ") {
+		if d, err := time.ParseDuration(strings.TrimPrefix(spec, "@every ")); err == nil {
+			return d
+		}
+	}
+
+	var min time.Duration
+	prev := sched.Next(now)
+	for i := 0; i < intervalSamples; i++ {
+		next := sched.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if d := next.Sub(prev); min == 0 || d < min {
+			min = d
+		}
+		prev = next
+	}
+	return min
+}
+
+// skipIfRunning wraps job so that a run is skipped while the previous one
+// is still in progress.
+func (a *pingAdapter) skipIfRunning(ctx context.Context, job cron.Job) cron.Job {
+	return cron.FuncJob(func() {
+		if !atomic.CompareAndSwapInt32(&a.running, 0, 1) {
+			a.mu.Lock()
+			spec := a.Schedule
+			a.mu.Unlock()
+			logging.FromContext(ctx).Infow("ping still running, skipping tick", zap.String("schedule", spec))
+			return
+		}
+		defer atomic.StoreInt32(&a.running, 0)
+		job.Run()
+	})
+}
+
+// cronTick sends one event to the sink. The returned error is non-nil when
+// the event could not be delivered.
+func (a *pingAdapter) cronTick(ctx context.Context) error {
+	a.inflight.Add(1)
+	atomic.AddInt32(&a.inflightCount, 1)
+	defer func() {
+		atomic.AddInt32(&a.inflightCount, -1)
+		a.inflight.Done()
+	}()
+
+	a.recordTick()
+
+	correlationID := uuid.New().String()
+	ctx = withCorrelationID(ctx, correlationID)
+
+	if !a.EndTime.IsZero() && !a.now().Before(a.EndTime) {
+		logging.FromContext(ctx).Infow("ping end time reached, stopping", zap.Time("endTime", a.EndTime))
+		recordSkipped(ctx, "end time reached")
+		a.terminate()
+		return nil
+	}
+
+	if a.maxEventsReached() {
+		recordSkipped(ctx, "max events reached")
+		return nil
+	}
+
+	if a.paused() {
+		logging.FromContext(ctx).Infow("ping paused, skipping tick", zap.String("pauseFile", a.PauseFile))
+		recordSkipped(ctx, "paused")
+		return nil
+	}
+
+	if a.inBlackout(a.now()) {
+		logging.FromContext(ctx).Infow("ping in a blackout window, skipping tick")
+		recordSkipped(ctx, "in a blackout window")
+		return nil
+	}
+
+	if !a.inBusinessHours(a.now()) {
+		logging.FromContext(ctx).Infow("ping outside business hours, skipping tick", zap.String("businessHours", a.BusinessHours.spec))
+		recordSkipped(ctx, "outside business hours")
+		return nil
+	}
+
+	if err := a.preconditionMet(ctx); err != nil {
+		logging.FromContext(ctx).Infow("ping precondition not met, skipping tick", zap.Error(err))
+		recordSkipped(ctx, "precondition not met: "+err.Error())
+		return nil
+	}
+
+	if a.CircuitBreakerThreshold > 0 && !a.breaker.allow(a.now(), a.CircuitBreakerCooldown) {
+		logging.FromContext(ctx).Info("ping circuit breaker open, skipping tick")
+		return errCircuitOpen
+	}
+
+	entry, isEntry := entryFrom(ctx)
+	scheduled, replay := replayFrom(ctx)
+	kind := kindFrom(ctx)
+	if replay {
+		kind = kindCatchup
+	}
+	if !replay {
+		scheduled = a.now()
+		if slot, ok := slotFrom(ctx); ok {
+			scheduled = slot
+			a.recordDrift(ctx, a.now().Sub(slot))
+		}
+	}
+	a.saveLastFired(ctx, scheduled)
+
+	if d := a.jitter(); d > 0 && !replay {
+		t := a.getClock().NewTimer(d)
+		select {
+		case <-t.C():
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+
+	// The send itself is not bound to the stop signal so that an event
+	// already on its way is not dropped.
+	stop := ctx.Done()
+	ctx, span := trace.StartSpan(detach(ctx), fmt.Sprintf("pingsource:%s.%s", a.Name, a.Namespace))
+	defer span.End()
+	if isEntry {
+		ctx = withEntry(ctx, entry)
+	}
+	if span.IsRecordingEvents() {
+		span.AddAttributes(
+			tracing.MessagingSystemAttribute,
+			tracing.MessagingProtocolHTTP,
+		)
+	}
+
+	if a.DataReload && a.DataFromFile != "" && !a.DataStream {
+		if err := a.loadData(); err != nil {
+			logging.FromContext(ctx).Errorw("ping failed to reload data, using the previous data", zap.Error(err))
+		}
+	}
+
+	if a.SendTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.SendTimeout)
+		defer cancel()
+	}
+
+	if a.RetryCount > 0 {
+		ctx = cloudevents.ContextWithRetriesExponentialBackoff(ctx, a.RetryBackoff, a.RetryCount)
+	}
+	ctx = a.withEncoding(ctx)
+
+	tick := templateData{
+		Time:          scheduled,
+		Name:          a.Name,
+		Namespace:     a.Namespace,
+		Sequence:      a.nextSequence(),
+		Replay:        replay,
+		kind:          kind,
+		correlationID: correlationID,
+	}
+	if a.DataStream && !isEntry {
+		event := a.newEvent(span, tick)
+		contentType := a.DataContentType
+		if contentType == "" {
+			contentType = octetStream
+		}
+		event.SetDataContentType(contentType)
+		if ok, err := a.limitFile(ctx, a.DataFromFile); !ok {
+			return err
+		}
+		if err := a.sendCounted(withStream(ctx, a.DataFromFile), event); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if _, env := a.Payload.(EnvPayload); a.Payload != nil && !env && !isEntry {
+		b, contentType, err := a.Payload.Payload(ctx)
+		if err != nil {
+			logging.FromContext(ctx).Errorw("ping failed to get the payload", zap.Error(err))
+			return err
+		}
+		if contentType == "" {
+			contentType = octetStream
+		}
+		event := a.newEvent(span, tick)
+		if err := event.SetData(contentType, b); err != nil {
+			logging.FromContext(ctx).Errorw("ping failed to set event data", zap.Error(err))
+			return err
+		}
+		if ok, err := a.limitEventData(ctx, &event); !ok {
+			return err
+		}
+		if err := a.sendCounted(ctx, event); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if a.RandomDataSize > 0 && !isEntry {
+		event := a.newEvent(span, tick)
+		contentType := a.DataContentType
+		if contentType == "" {
+			contentType = octetStream
+		}
+		if err := event.SetData(contentType, randomData(a.RandomDataSize)); err != nil {
+			logging.FromContext(ctx).Errorw("ping failed to set event data", zap.Error(err))
+			return err
+		}
+		if ok, err := a.limitEventData(ctx, &event); !ok {
+			return err
+		}
+		if err := a.sendCounted(ctx, event); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	b, contentType, err := a.envPayload(entry, isEntry).Payload(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Errorw("ping failed to get the payload", zap.Error(err))
+		return err
+	}
+	data := string(b)
+	if a.DataTemplate {
+		var err error
+		data, err = a.render(data, tick)
+		if err != nil {
+			logging.FromContext(ctx).Errorw("ping failed to render data template", zap.Error(err))
+			return err
+		}
+	}
+
+	items := []batchItem{{Data: data, ContentType: contentType}}
+	if a.Batch {
+		var err error
+		if items, err = batchItems(data); err != nil {
+			logging.FromContext(ctx).Errorw("ping failed to split batch", zap.Error(err))
+			return err
+		}
+	}
+
+	// Static data was validated by start.
+	if a.DataTemplate && a.schema != nil {
+		for _, item := range items {
+			if err := a.validatePayload(ctx, item.Data, tick); err != nil {
+				logging.FromContext(ctx).Errorw("ping skipping tick with invalid data", zap.Error(err))
+				return err
+			}
+		}
+	}
+
+	if !a.Batch {
+		event := a.newEvent(span, tick)
+		if err := a.setEventData(ctx, &event, data, contentType, tick); err != nil {
+			logging.FromContext(ctx).Errorw("ping failed to set event data", zap.Error(err))
+			return err
+		}
+		if ok, err := a.limitEventData(ctx, &event); !ok {
+			return err
+		}
+		if err := a.sendCounted(ctx, event); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	events := make([]cloudevents.Event, 0, len(items))
+	for i, item := range items {
+		if i > 0 {
+			tick.Sequence = a.nextSequence()
+		}
+		if item.ContentType == "" {
+			item.ContentType = contentType
+		}
+		event := a.newEvent(span, tick)
+		if err := a.setEventData(ctx, &event, item.Data, item.ContentType, tick); err != nil {
+			logging.FromContext(ctx).Errorw("ping failed to set event data", zap.Error(err))
+			return err
+		}
+		ok, err := a.limitEventData(ctx, &event)
+		if err != nil {
+			return err
+		}
+		if ok {
+			events = append(events, event)
+		}
+	}
+	if failed := a.sendBatch(ctx, stop, events); failed > 0 {
+		return fmt.Errorf("failed to send %d of %d batched cloudevents", failed, len(items))
+	}
+	return nil
+}
+
+// terminate stops a running start.
+func (a *pingAdapter) terminate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stop != nil {
+		a.stop()
+	}
+}
+
+// sendCounted sends event counted against the MaxEvents. The event is
+// not sent, without error, once MaxEvents are sent or being sent.
+func (a *pingAdapter) sendCounted(ctx context.Context, event cloudevents.Event) error {
+	if !a.reserveSent() {
+		recordSkipped(ctx, "max events reached")
+		return nil
+	}
+	if err := a.send(ctx, event); err != nil {
+		a.releaseSent()
+		return err
+	}
+	a.countSent(ctx)
+	return nil
+}
+
+// reserveSent reserves the send of an event among the MaxEvents, so that
+// concurrent ticks do not send more. It returns false when all of them
+// are sent or being sent.
+func (a *pingAdapter) reserveSent() bool {
+	if a.MaxEvents <= 0 {
+		return true
+	}
+	for {
+		n := atomic.LoadInt32(&a.reserved)
+		if n >= a.MaxEvents {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&a.reserved, n, n+1) {
+			return true
+		}
+	}
+}
+
+// releaseSent releases the reservation of an event which failed to send.
+func (a *pingAdapter) releaseSent() {
+	if a.MaxEvents > 0 {
+		atomic.AddInt32(&a.reserved, -1)
+	}
+}
+
+// countSent counts a successfully sent event, stopping start once
+// MaxEvents are sent.
+func (a *pingAdapter) countSent(ctx context.Context) {
+	if n := atomic.AddInt32(&a.sent, 1); a.MaxEvents > 0 && n == a.MaxEvents {
+		logging.FromContext(ctx).Infow("ping max events sent, stopping", zap.Int32("maxEvents", a.MaxEvents))
+		a.terminate()
+	}
+}
+
+// maxEventsReached reports whether MaxEvents are already sent or being
+// sent.
+func (a *pingAdapter) maxEventsReached() bool {
+	return a.MaxEvents > 0 && atomic.LoadInt32(&a.reserved) >= a.MaxEvents
+}
+
+// loggerContext returns a context carrying the logger of the adapter.
+func (a *pingAdapter) loggerContext() context.Context {
+	if a.logger == nil {
+		return context.Background()
+	}
+	return logging.WithLogger(context.Background(), a.logger)
+}
+
+// runContext returns the context of the running start, the context of
+// the logger of the adapter when it is not running.
+func (a *pingAdapter) runContext() context.Context {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.ctx != nil {
+		return a.ctx
+	}
+	return a.loggerContext()
+}
+
+// detach returns a context which is not cancelled with ctx, carrying its
+// logger and the recorder of the sent ids.
+func detach(ctx context.Context) context.Context {
+	detached := logging.WithLogger(context.Background(), logging.FromContext(ctx))
+	if ids, ok := ctx.Value(sentIDsKey{}).(*sentIDs); ok {
+		detached = withSentIDs(detached, ids)
+	}
+	return detached
+}
+
+// newEvent returns the event of tick without data, traced by span.
+func (a *pingAdapter) newEvent(span *trace.Span, tick templateData) cloudevents.Event {
+	event := cloudevents.NewEvent(a.specVersion())
+	if a.IDStrategy == idStrategySequence {
+		event.SetID(fmt.Sprintf("%s-%s-%d", a.Namespace, a.Name, tick.Sequence))
+	} else {
+		// Set before sending, rather than by the client, to be logged.
+		event.SetID(a.newID())
+	}
+	event.SetType(a.eventType())
+	event.SetSource(a.source())
+	for name, value := range a.Extensions {
+		event.SetExtension(name, value)
+	}
+	event.SetExtension(sequenceExtension, tick.Sequence)
+	if a.EmitVersion {
+		event.SetExtension(versionExtension, version)
+	}
+	event.SetExtension(scheduledTimeExtension, a.formatTime(tick.Time))
+	event.SetExtension(firedTimeExtension, a.formatTime(a.now()))
+	if tick.Replay || a.TimeSource == timeSourceScheduled {
+		event.SetTime(tick.Time)
+	}
+	if tick.Replay {
+		event.SetExtension(replayExtension, true)
+	}
+	kind := tick.kind
+	if kind == "" {
+		kind = kindScheduled
+	}
+	event.SetExtension(kindExtension, string(kind))
+	if tick.correlationID != "" {
+		event.SetExtension(correlationIDExtension, tick.correlationID)
+	}
+	if a.Subject != "" {
+		event.SetSubject(a.expand(a.Subject))
+	}
+	if a.PartitionKey != "" {
+		event.SetExtension(partitionKeyExtension, a.expand(a.PartitionKey))
+	}
+	if a.DataSchema != "" {
+		event.SetDataSchema(a.DataSchema)
+	}
+	if sc := span.SpanContext(); sc.IsSampled() {
+		ceextensions.FromSpanContext(sc).AddTracingAttributes(&event)
+	}
+	return event
+}
+
+// maxRandomDataSize is the maximum RandomDataSize.
+const maxRandomDataSize = 1 << 20
+
+// randomData returns size random bytes.
+func randomData(size int) []byte {
+	b := make([]byte, size)
+	rand.Read(b)
+	return b
+}
+
+// batchItem is the data of one event of a batch.
+type batchItem struct {
 	Data string
+	// ContentType overrides the DataContentType when set.
+	ContentType string
+}
 
-	// Name is the name of the adapter.
-	Name string
+// batchItems splits data, a JSON array, into the data of its elements.
+// String elements are unquoted. An object of only data and contentType
+// members is the data of the element, with its content type.
+func batchItems(data string) ([]batchItem, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("invalid batch data: must be a JSON array: %v", err)
+	}
+	items := make([]batchItem, len(raw))
+	for i, item := range raw {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(item, &obj); err == nil && len(obj) == 2 && obj["data"] != nil && obj["contentType"] != nil {
+			if err := json.Unmarshal(obj["contentType"], &items[i].ContentType); err != nil {
+				return nil, fmt.Errorf("invalid content type of batch element %d: must be a string", i)
+			}
+			item = obj["data"]
+		}
+		if err := json.Unmarshal(item, &items[i].Data); err != nil {
+			items[i].Data = string(item)
+		}
+	}
+	return items, nil
+}
 
-	// Namespace is the namespace of the adapter.
-	Namespace string
+// setEventData sets data as the payload of the event of tick. contentType
+// overrides the DataContentType when set.
+func (a *pingAdapter) setEventData(ctx context.Context, event *cloudevents.Event, data, contentType string, tick templateData) error {
+	if contentType == "" {
+		contentType = a.DataContentType
+	}
+	if a.DataBase64 {
+		b, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return fmt.Errorf("invalid base64 data: %v", err)
+		}
+		if contentType == "" {
+			contentType = octetStream
+		}
+		return event.SetData(contentType, b)
+	}
+	if contentType != "" {
+		return event.SetData(contentType, []byte(data))
+	}
+	return event.SetData(cloudevents.ApplicationJSON, a.message(ctx, data, tick))
+}
 
-	// client sends cloudevents.
-	Client cloudevents.Client
+// loadData reads the data from DataFromFile.
+func (a *pingAdapter) loadData() error {
+	b, err := ioutil.ReadFile(a.DataFromFile)
+	if err != nil {
+		return fmt.Errorf("failed to read data file %s: %v", a.DataFromFile, err)
+	}
+	a.mu.Lock()
+	a.fileData = string(b)
+	a.mu.Unlock()
+	return nil
 }
 
-func init() {
-	_ = os.Setenv("K_RESOURCE_GROUP", "pingsources.sources.knative.dev")
+// data returns the data to send, one of DataVariants when set, and its
+// content type overriding the DataContentType, if any.
+func (a *pingAdapter) data() (string, string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.DataVariants) > 0 {
+		v := a.pickVariant()
+		return v.Data, v.ContentType
+	}
+	if a.DataFromFile == "" {
+		return a.Data, ""
+	}
+	return a.fileData, ""
 }
 
-func NewEnvConfig() adapter.EnvConfigAccessor {
-	return &envConfig{}
+// envPayload returns the EnvPayload of the data of the tick: the data of
+// entry, the Payload when it is an EnvPayload, or the data otherwise.
+func (a *pingAdapter) envPayload(entry scheduleEntry, isEntry bool) EnvPayload {
+	if isEntry {
+		return EnvPayload{Data: entry.Data}
+	}
+	if p, ok := a.Payload.(EnvPayload); ok {
+		return p
+	}
+	data, contentType := a.data()
+	return EnvPayload{Data: data, ContentType: contentType}
 }
 
-func NewAdapter(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
-	env := processed.(*envConfig)
+// withEncoding returns ctx forcing the Encoding of the events sent with it.
+func (a *pingAdapter) withEncoding(ctx context.Context) context.Context {
+	return contextWithEncoding(ctx, a.Encoding)
+}
 
-	return &pingAdapter{
-		Schedule:  env.Schedule,
-		Data:      env.Data,
-		Name:      env.Name,
-		Namespace: env.Namespace,
-		Client:    ceClient,
+// contextWithEncoding returns ctx forcing the encoding enc of the events
+// sent with it.
+func contextWithEncoding(ctx context.Context, enc encoding) context.Context {
+	switch enc {
+	case encodingBinary:
+		return cloudevents.WithEncodingBinary(ctx)
+	case encodingStructured:
+		return cloudevents.WithEncodingStructured(ctx)
+	default:
+		return ctx
 	}
 }
 
-func (a *pingAdapter) Start(ctx context.Context) error {
-	return a.start(ctx.Done())
+// ErrInvalidSchedule is the error of a schedule which cannot be parsed. It
+// is a configuration error, which retrying doesn't fix.
+type ErrInvalidSchedule struct {
+	// Schedule is the unparseable schedule.
+	Schedule string
+	// Err is the parse error.
+	Err error
+
+	// missingSeconds hints at SECONDS_FIELD for a 6 fields schedule.
+	missingSeconds bool
 }
 
-func (a *pingAdapter) start(stopCh <-chan struct{}) error {
-	sched, err := cron.ParseStandard(a.Schedule)
+func (e *ErrInvalidSchedule) Error() string {
+	if e.missingSeconds {
+		return fmt.Sprintf("unparseable schedule %s: %v (set SECONDS_FIELD to use a seconds field)", e.Schedule, e.Err)
+	}
+	return fmt.Sprintf("unparseable schedule %s: %v", e.Schedule, e.Err)
+}
+
+func (e *ErrInvalidSchedule) Unwrap() error {
+	return e.Err
+}
+
+// parseSchedule parses spec and checks it doesn't fire more often than
+// MinInterval.
+func (a *pingAdapter) parseSchedule(spec string) (cron.Schedule, error) {
+	parse := cron.ParseStandard
+	if a.SecondsField {
+		parse = secondsParser.Parse
+	}
+	sched, err := parse(spec)
 	if err != nil {
-		return fmt.Errorf("unparseable schedule %s: %v", a.Schedule, err)
+		return nil, &ErrInvalidSchedule{
+			Schedule:       spec,
+			Err:            err,
+			missingSeconds: !a.SecondsField && len(strings.Fields(spec)) == 6,
+		}
 	}
 
-	c := cron.New()
-	c.Schedule(sched, cron.FuncJob(a.cronTick))
-	c.Start()
-	<-stopCh
-	c.Stop()
+	if a.MinInterval > 0 {
+		if interval := scheduleInterval(spec, sched, a.now()); interval < a.MinInterval {
+			return nil, fmt.Errorf("schedule %s fires every %v, more often than the minimum interval %v", spec, interval, a.MinInterval)
+		}
+	}
+	return sched, nil
+}
+
+// validateData checks data can be decoded and rendered as configured.
+func (a *pingAdapter) validateData(ctx context.Context, data string) error {
+	if a.DataBase64 {
+		if _, err := base64.StdEncoding.DecodeString(data); err != nil {
+			return fmt.Errorf("invalid base64 data: %v", err)
+		}
+	}
+
+	if a.DataTemplate {
+		_, err := a.dataTemplate(data)
+		return err
+	}
+
+	items := []batchItem{{Data: data}}
+	if a.Batch {
+		var err error
+		if items, err = batchItems(data); err != nil {
+			return err
+		}
+	}
+	if a.schema != nil {
+		tick := templateData{Time: a.now(), Name: a.Name, Namespace: a.Namespace}
+		for _, item := range items {
+			if err := a.validatePayload(ctx, item.Data, tick); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
-func (a *pingAdapter) cronTick() {
-	ctx := context.Background()
+func (a *pingAdapter) getClock() Clock {
+	if a.clock == nil {
+		return clock.RealClock{}
+	}
+	return a.clock
+}
+
+func (a *pingAdapter) now() time.Time {
+	return a.getClock().Now()
+}
+
+// inTimezone returns t in the Timezone the schedule is evaluated in, or
+// unchanged without Timezone.
+func (a *pingAdapter) inTimezone(t time.Time) time.Time {
+	if a.Timezone != "" {
+		if loc, err := time.LoadLocation(a.Timezone); err == nil {
+			return t.In(loc)
+		}
+	}
+	return t
+}
+
+// jitter returns a random duration in [0, JitterMax), capped to the time
+// left until the next tick.
+func (a *pingAdapter) jitter() time.Duration {
+	a.mu.Lock()
+	sched := a.schedule
+	a.mu.Unlock()
+
+	max := a.JitterMax
+	if sched != nil {
+		now := a.now()
+		if left := sched.Next(now).Sub(now); left < max {
+			max = left
+		}
+	}
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// nextSequence increments and returns the event sequence number.
+func (a *pingAdapter) nextSequence() int32 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sequence++
+	return a.sequence
+}
+
+func (a *pingAdapter) eventType() string {
+	if a.EventType != "" {
+		return a.EventType
+	}
+	return sourcesv1alpha2.PingSourceEventType
+}
 
-	// Simple retry configuration to be less than 1mn.
-	// We might want to retry more times for less-frequent schedule.
-	ctx = cloudevents.ContextWithRetriesExponentialBackoff(ctx, 50*time.Millisecond, 5)
+func (a *pingAdapter) specVersion() string {
+	if a.SpecVersion != "" {
+		return string(a.SpecVersion)
+	}
+	return cloudevents.VersionV1
+}
 
-	event := cloudevents.NewEvent(cloudevents.VersionV1)
-	event.SetType(sourcesv1alpha2.PingSourceEventType)
-	event.SetSource(sourcesv1alpha2.PingSourceSource(a.Namespace, a.Name))
-	if err := event.SetData(cloudevents.ApplicationJSON, message(a.Data)); err != nil {
-		logging.FromContext(ctx).Errorw("ping failed to set event data", zap.Error(err))
+func (a *pingAdapter) source() string {
+	if a.Source != "" {
+		return a.Source
 	}
+	return sourcesv1alpha2.PingSourceSource(a.Namespace, a.Name)
+}
+
+// expand replaces the {namespace} and {name} placeholders in s.
+func (a *pingAdapter) expand(s string) string {
+	return strings.NewReplacer("{namespace}", a.Namespace, "{name}", a.Name).Replace(s)
+}
 
-	if err := a.Client.Send(ctx, event); err != nil {
-		logging.FromContext(ctx).Errorw("ping failed to send cloudevent", zap.Error(err))
+// newID returns a random event id, with the IDPrefix.
+func (a *pingAdapter) newID() string {
+	if a.IDPrefix != "" {
+		return a.IDPrefix + "-" + uuid.New().String()
 	}
+	return uuid.New().String()
+}
+
+// isValidIDPrefix reports whether p is made of letters, digits, '.', '_'
+// and '-' only.
+func isValidIDPrefix(p string) bool {
+	return p != "" && strings.IndexFunc(p, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && !strings.ContainsRune("._-", r)
+	}) == -1
+}
+
+// isValidEventType reports whether t can be used as a CloudEvent type,
+// that is a non-empty string without whitespace or control characters.
+func isValidEventType(t string) bool {
+	return t != "" && strings.IndexFunc(t, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}) == -1
 }
 
 type Message struct {
 	Body string `json:"body"`
 }
 
-func message(body string) interface{} {
+// wrap returns the Message of body, in its MessageWrapField and with the
+// metadata of tick with MessageWrapMeta.
+func (a *pingAdapter) wrap(body string, tick templateData) interface{} {
+	field := a.MessageWrapField
+	if field == "" {
+		field = "body"
+	}
+	if field == "body" && !a.MessageWrapMeta {
+		return Message{Body: body}
+	}
+	msg := map[string]interface{}{field: body}
+	if a.MessageWrapMeta {
+		msg["name"] = tick.Name
+		msg["namespace"] = tick.Namespace
+		msg["time"] = a.formatTime(tick.Time)
+	}
+	return msg
+}
+
+// message returns the JSON payload of body. A JSON object body, or YAML
+// mapping with the YAML DataFormat, is merged with the MessageFields of
+// tick. Any other body, or any body with RawData, is wrapped in a Message.
+func (a *pingAdapter) message(ctx context.Context, body string, tick templateData) interface{} {
+	if a.RawData {
+		return a.wrap(body, tick)
+	}
+
+	b := []byte(body)
+	if a.DataFormat == dataFormatYAML {
+		var err error
+		if b, err = yaml.YAMLToJSON(b); err != nil {
+			logging.FromContext(ctx).Warnw("ping data is not valid YAML, wrapping it in a message", zap.Error(err))
+			return a.wrap(body, tick)
+		}
+	}
+
 	// try to marshal the body into an interface.
 	var obj map[string]*json.RawMessage
-	if err := json.Unmarshal([]byte(body), &obj); err != nil {
+	if err := json.Unmarshal(b, &obj); err != nil || (obj == nil && a.DataFormat == dataFormatYAML) {
 		//default to a wrapped message.
-		return Message{Body: body}
+		return a.wrap(body, tick)
+	}
+
+	for _, field := range a.MessageFields {
+		if _, ok := obj[field]; ok {
+			continue
+		}
+		var value interface{}
+		switch field {
+		case messageFieldTime:
+			value = a.formatTime(tick.Time)
+		case messageFieldSequence:
+			value = tick.Sequence
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		if obj == nil {
+			obj = make(map[string]*json.RawMessage, len(a.MessageFields))
+		}
+		msg := json.RawMessage(raw)
+		obj[field] = &msg
 	}
 	return obj
 }
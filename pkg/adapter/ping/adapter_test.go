@@ -17,14 +17,40 @@ limitations under the License.
 package ping
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/cloudevents/sdk-go/v2/types"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"knative.dev/eventing/pkg/adapter/v2"
 	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+	sourcesv1alpha2 "knative.dev/eventing/pkg/apis/sources/v1alpha2"
 )
 
 func TestStart_ServeHTTP(t *testing.T) {
@@ -67,7 +93,7 @@ func TestStart_ServeHTTP(t *testing.T) {
 				}
 			}()
 
-			a.cronTick() // force a tick.
+			a.cronTick(context.Background()) // force a tick.
 			validateSent(t, ce, tc.data)
 
 			log.Print("test done")
@@ -90,80 +116,2093 @@ func TestStartBadCron(t *testing.T) {
 	}
 }
 
-func TestPostMessage_ServeHTTP(t *testing.T) {
+func TestEnvConfigValidate(t *testing.T) {
+	valid := func() *envConfig {
+		return &envConfig{
+			EnvConfig:     adapter.EnvConfig{Sink: "http://sink.example.com"},
+			Schedule:      "* * * * *",
+			Data:          "data",
+			SkipIfRunning: true,
+			RetryCount:    5,
+			RetryBackoff:  50 * time.Millisecond,
+			MinInterval:   time.Second,
+		}
+	}
 	testCases := map[string]struct {
-		sink  func(http.ResponseWriter, *http.Request)
-		data  string
-		error bool
+		mutate func(*envConfig)
+		error  bool
 	}{
-		"happy": {
-			sink: sinkAccepted,
-			data: `{"body":"data"}`,
+		"valid": {
+			mutate: func(*envConfig) {},
 		},
-		"rejected": {
-			sink:  sinkRejected,
-			data:  `{"body":"data"}`,
-			error: true,
+		"unparseable schedule": {
+			mutate: func(e *envConfig) { e.Schedule = "bogus" },
+			error:  true,
+		},
+		"schedule below min interval": {
+			mutate: func(e *envConfig) { e.Schedule = "@every 1s"; e.MinInterval = time.Minute },
+			error:  true,
+		},
+		"empty data": {
+			mutate: func(e *envConfig) { e.Data = "" },
+			error:  true,
+		},
+		"random data": {
+			mutate: func(e *envConfig) { e.Data = ""; e.RandomDataSize = 16 },
+		},
+		"random data size too large": {
+			mutate: func(e *envConfig) { e.RandomDataSize = maxRandomDataSize + 1 },
+			error:  true,
+		},
+		"env payload provider": {
+			mutate: func(e *envConfig) { e.PayloadProvider = "env" },
+		},
+		"file payload provider without data file": {
+			mutate: func(e *envConfig) { e.PayloadProvider = "file" },
+			error:  true,
+		},
+		"unknown payload provider": {
+			mutate: func(e *envConfig) { e.PayloadProvider = "bogus" },
+			error:  true,
+		},
+		"data and data file": {
+			mutate: func(e *envConfig) { e.DataFromFile = "/data" },
+			error:  true,
+		},
+		"invalid event type": {
+			mutate: func(e *envConfig) { e.EventType = "ping type" },
+			error:  true,
+		},
+		"negative retry count": {
+			mutate: func(e *envConfig) { e.RetryCount = -1 },
+			error:  true,
+		},
+		"invalid base64": {
+			mutate: func(e *envConfig) { e.Data = "not base64!"; e.DataBase64 = true },
+			error:  true,
+		},
+		"invalid template": {
+			mutate: func(e *envConfig) { e.Data = "{{"; e.DataTemplate = true },
+			error:  true,
+		},
+		"negative send timeout": {
+			mutate: func(e *envConfig) { e.SendTimeout = -time.Second },
+			error:  true,
+		},
+		"id prefix": {
+			mutate: func(e *envConfig) { e.IDPrefix = "prod-eu_1.a" },
+		},
+		"unsafe id prefix": {
+			mutate: func(e *envConfig) { e.IDPrefix = "prod/eu" },
+			error:  true,
+		},
+		"id prefix with the sequence strategy": {
+			mutate: func(e *envConfig) { e.IDPrefix = "prod"; e.IDStrategy = idStrategySequence },
+			error:  true,
+		},
+		"raw yaml data": {
+			mutate: func(e *envConfig) { e.RawData = true; e.DataFormat = dataFormatYAML },
+			error:  true,
+		},
+		"relative sink": {
+			mutate: func(e *envConfig) { e.Sinks = []string{"sink"} },
+			error:  true,
+		},
+		"relative dead letter sink": {
+			mutate: func(e *envConfig) { e.DeadLetterSink = "dls" },
+			error:  true,
+		},
+		"relative data schema": {
+			mutate: func(e *envConfig) { e.DataSchema = "schema.json" },
+			error:  true,
+		},
+		"invalid timezone": {
+			mutate: func(e *envConfig) { e.Timezone = "Mars/Olympus" },
+			error:  true,
+		},
+		"missing client certificate": {
+			mutate: func(e *envConfig) { e.TLSClientCert = "/missing/tls.crt"; e.TLSClientKey = "/missing/tls.key" },
+			error:  true,
+		},
+		"past end time": {
+			mutate: func(e *envConfig) { e.EndTime = time.Now().Add(-time.Hour) },
+			error:  true,
+		},
+		"negative circuit breaker threshold": {
+			mutate: func(e *envConfig) { e.CircuitBreakerThreshold = -1 },
+			error:  true,
+		},
+		"circuit breaker without cooldown": {
+			mutate: func(e *envConfig) { e.CircuitBreakerThreshold = 3; e.CircuitBreakerCooldown = 0 },
+			error:  true,
+		},
+		"negative max events": {
+			mutate: func(e *envConfig) { e.MaxEvents = -1 },
+			error:  true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			env := valid()
+			tc.mutate(env)
+			if err := env.Validate(context.Background()); tc.error != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEnvConfigValidateEmptyData(t *testing.T) {
+	// The receive adapter of a PingSource without jsonData.
+	os.Setenv("DATA", "")
+	defer os.Unsetenv("DATA")
+	env := &envConfig{
+		EnvConfig: adapter.EnvConfig{Sink: "http://sink.example.com"},
+		Schedule:  "* * * * *",
+	}
+	if err := env.Validate(context.Background()); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	ce := adaptertest.NewTestClient()
+	a := newPingAdapter(env, ce)
+	if _, _, err := a.prepare(context.Background()); err != nil {
+		t.Fatalf("prepare() = %v", err)
+	}
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("cronTick() = %v", err)
+	}
+	validateSent(t, ce, `{"body":""}`)
+}
+
+func TestEnvConfigValidateFiles(t *testing.T) {
+	env := &envConfig{
+		EnvConfig:      adapter.EnvConfig{Sink: "http://sink.example.com"},
+		Schedule:       "* * * * *",
+		DataFromFile:   "/missing/data.json",
+		ValidateSchema: "/missing/schema.json",
+	}
+	// The files are only read once the adapter starts.
+	if err := env.Validate(context.Background()); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if _, _, err := newPingAdapter(env, nil).prepare(context.Background()); err == nil {
+		t.Error("Expected prepare() to fail reading the files")
+	}
+}
+
+func TestStartSecondsField(t *testing.T) {
+	testCases := map[string]struct {
+		schedule     string
+		secondsField bool
+		wantInterval time.Duration
+		error        bool
+	}{
+		"seconds with flag": {
+			schedule:     "*/15 * * * * *",
+			secondsField: true,
+			wantInterval: 15 * time.Second,
+		},
+		"seconds without flag": {
+			schedule: "*/15 * * * * *",
+			error:    true,
+		},
+		"minutes with flag": {
+			schedule:     "* * * * *",
+			secondsField: true,
+			error:        true,
 		},
 	}
 	for n, tc := range testCases {
 		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{
+				Schedule:     tc.schedule,
+				SecondsField: tc.secondsField,
+			}
+
+			stop := make(chan struct{})
+			close(stop)
+			err := a.start(stop)
+			if tc.error != (err != nil) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.error {
+				return
+			}
+
+			now := time.Now()
+			next := a.schedule.Next(now)
+			if got := a.schedule.Next(next).Sub(next); got != tc.wantInterval {
+				t.Errorf("Expected interval %v, got %v", tc.wantInterval, got)
+			}
+		})
+	}
+}
+
+func TestErrInvalidSchedule(t *testing.T) {
+	a := &pingAdapter{
+		Schedule: "every blue moon",
+		Data:     "data",
+	}
+	stop := make(chan struct{})
+	close(stop)
+	err := a.start(stop)
+
+	var invalid *ErrInvalidSchedule
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected start to return an ErrInvalidSchedule, got %v", err)
+	}
+	if invalid.Schedule != "every blue moon" || invalid.Err == nil {
+		t.Errorf("Expected the invalid schedule and its parse error, got %q and %v", invalid.Schedule, invalid.Err)
+	}
+
+	env := &envConfig{
+		EnvConfig: adapter.EnvConfig{Sink: "http://sink.example.com"},
+		Schedule:  "*/15 * * * * *",
+		Data:      "data",
+	}
+	err = env.Validate(context.Background())
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected Validate to return an ErrInvalidSchedule, got %v", err)
+	}
+	if invalid.Schedule != env.Schedule {
+		t.Errorf("Expected the invalid schedule %q, got %q", env.Schedule, invalid.Schedule)
+	}
+	if !strings.Contains(err.Error(), "SECONDS_FIELD") {
+		t.Errorf("Expected the error to hint at SECONDS_FIELD, got %v", err)
+	}
+
+	// A schedule firing too often is not unparseable.
+	a = &pingAdapter{
+		Schedule:    "@every 1s",
+		Data:        "data",
+		MinInterval: time.Minute,
+	}
+	if err := a.start(stop); err == nil || errors.As(err, &invalid) {
+		t.Errorf("Expected an error other than ErrInvalidSchedule, got %v", err)
+	}
+}
+
+func TestStartMinInterval(t *testing.T) {
+	testCases := map[string]struct {
+		schedule    string
+		minInterval time.Duration
+		error       bool
+	}{
+		"every minute": {
+			schedule:    "@every 1m",
+			minInterval: time.Second,
+		},
+		"every 100ms": {
+			schedule:    "@every 100ms",
+			minInterval: time.Second,
+			error:       true,
+		},
+		"standard cron": {
+			schedule:    "*/5 * * * *",
+			minInterval: time.Second,
+		},
+		"standard cron too frequent": {
+			schedule:    "* * * * *",
+			minInterval: 5 * time.Minute,
+			error:       true,
+		},
+		"irregular cron": {
+			schedule:    "0,1 9 * * *",
+			minInterval: 2 * time.Minute,
+			error:       true,
+		},
+		"disabled": {
+			schedule: "@every 100ms",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{
+				Schedule:    tc.schedule,
+				MinInterval: tc.minInterval,
+			}
+
+			stop := make(chan struct{})
+			close(stop)
+			err := a.start(stop)
+			if tc.error && err == nil {
+				t.Error("expected error, got nil")
+			} else if !tc.error && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestStartTimezone(t *testing.T) {
+	testCases := map[string]struct {
+		timezone string
+		error    bool
+	}{
+		"default": {},
+		"valid": {
+			timezone: "America/New_York",
+		},
+		"invalid": {
+			timezone: "Mars/Olympus_Mons",
+			error:    true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{
+				Schedule: "* * * * *",
+				Timezone: tc.timezone,
+			}
+
+			stop := make(chan struct{})
+			close(stop)
+			err := a.start(stop)
+			if tc.error && err == nil {
+				t.Error("expected error, got nil")
+			} else if !tc.error && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestStartBadEventType(t *testing.T) {
+	a := &pingAdapter{
+		Schedule:  "* * * * *",
+		EventType: "not a type",
+	}
 
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestEventType(t *testing.T) {
+	testCases := map[string]struct {
+		eventType string
+		want      string
+	}{
+		"default": {
+			want: sourcesv1alpha2.PingSourceEventType,
+		},
+		"custom": {
+			eventType: "com.example.ping",
+			want:      "com.example.ping",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
 			ce := adaptertest.NewTestClient()
 
 			a := &pingAdapter{
-				Data:   "data",
-				Client: ce,
+				Data:      "data",
+				EventType: tc.eventType,
+				Client:    ce,
 			}
 
-			a.cronTick()
-			validateSent(t, ce, tc.data)
+			a.cronTick(context.Background())
+			sent := ce.Sent()
+			if len(sent) != 1 {
+				t.Fatalf("Expected 1 event to be sent, got %d", len(sent))
+			}
+			if got := sent[0].Type(); got != tc.want {
+				t.Errorf("Expected event type %q, got %q", tc.want, got)
+			}
 		})
 	}
 }
 
-func TestMessage(t *testing.T) {
+func TestStartBadEventSource(t *testing.T) {
+	a := &pingAdapter{
+		Schedule: "* * * * *",
+		Source:   "http://[::1",
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestEventSource(t *testing.T) {
 	testCases := map[string]struct {
-		body string
-		want string
+		source string
+		want   string
 	}{
-		"json simple": {
-			body: `{"message": "Hello world!"}`,
-			want: `{"message":"Hello world!"}`,
+		"default": {
+			want: sourcesv1alpha2.PingSourceSource("ns", "name"),
 		},
-		"json complex": {
-			body: `{"message": "Hello world!","extra":{"a":"sub", "b":[1,2,3]}}`,
-			want: `{"extra":{"a":"sub","b":[1,2,3]},"message":"Hello world!"}`,
+		"custom": {
+			source: "https://example.com/producer",
+			want:   "https://example.com/producer",
 		},
-		"string": {
-			body: "Hello, World!",
-			want: `{"body":"Hello, World!"}`,
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+
+			a := &pingAdapter{
+				Data:      "data",
+				Name:      "name",
+				Namespace: "ns",
+				Source:    tc.source,
+				Client:    ce,
+			}
+
+			a.cronTick(context.Background())
+			sent := ce.Sent()
+			if len(sent) != 1 {
+				t.Fatalf("Expected 1 event to be sent, got %d", len(sent))
+			}
+			if got := sent[0].Source(); got != tc.want {
+				t.Errorf("Expected event source %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestJitter(t *testing.T) {
+	testCases := map[string]struct {
+		schedule  string
+		jitterMax time.Duration
+		max       time.Duration
+	}{
+		"disabled": {},
+		"no schedule": {
+			jitterMax: time.Second,
+			max:       time.Second,
+		},
+		"capped by schedule": {
+			schedule:  "@every 1s",
+			jitterMax: time.Hour,
+			max:       time.Second,
 		},
 	}
 	for n, tc := range testCases {
 		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{
+				JitterMax: tc.jitterMax,
+			}
+			if tc.schedule != "" {
+				sched, err := cron.ParseStandard(tc.schedule)
+				if err != nil {
+					t.Fatalf("failed to parse schedule: %v", err)
+				}
+				a.schedule = sched
+			}
+
+			for i := 0; i < 100; i++ {
+				if got := a.jitter(); got < 0 || (got > 0 && got >= tc.max) {
+					t.Fatalf("Expected jitter in [0, %v), got %v", tc.max, got)
+				}
+			}
+		})
+	}
+}
 
-			m := message(tc.body)
+func TestJitterCancelled(t *testing.T) {
+	ce := adaptertest.NewTestClient()
 
-			j, err := json.Marshal(m)
-			if err != nil {
-				t.Errorf("failed to marshel message: %v", err)
+	a := &pingAdapter{
+		Data:      "data",
+		JitterMax: time.Hour,
+		Client:    ce,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		a.cronTick(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cronTick did not return after cancellation")
+	}
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected no event to be sent, got %d", got)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+
+	a := &pingAdapter{
+		Data:   "data",
+		Client: ce,
+	}
+
+	a.cronTick(context.Background())
+	a.cronTick(context.Background())
+
+	sent := ce.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 events to be sent, got %d", len(sent))
+	}
+	for i, event := range sent {
+		got, err := types.ToInteger(event.Extensions()[sequenceExtension])
+		if err != nil {
+			t.Fatalf("Expected %s extension, got error: %v", sequenceExtension, err)
+		}
+		if want := int32(i + 1); got != want {
+			t.Errorf("Expected sequence %d, got %d", want, got)
+		}
+	}
+}
+
+func TestBatch(t *testing.T) {
+	var mu sync.Mutex
+	var received []cloudevents.Event
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event, err := binding.ToEvent(r.Context(), cehttp.NewMessageFromHttpRequest(r))
+		if err != nil {
+			t.Errorf("failed to decode event: %v", err)
+		}
+		mu.Lock()
+		received = append(received, *event)
+		mu.Unlock()
+		sinkAccepted(w, r)
+	}))
+	defer sink.Close()
+
+	a := &pingAdapter{
+		Data:   `[{"n":1},{"n":2},"three"]`,
+		Batch:  true,
+		Client: newSinkClient(t, sink.URL),
+	}
+
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantData := []string{`{"n":1}`, `{"n":2}`, `{"body":"three"}`}
+	if len(received) != len(wantData) {
+		t.Fatalf("Expected %d events to be sent, got %d", len(wantData), len(received))
+	}
+	ids := sets.NewString()
+	for i, event := range received {
+		ids.Insert(event.ID())
+		if got := string(event.Data()); got != wantData[i] {
+			t.Errorf("Expected data %q, got %q", wantData[i], got)
+		}
+		got, err := types.ToInteger(event.Extensions()[sequenceExtension])
+		if err != nil {
+			t.Fatalf("Expected %s extension, got error: %v", sequenceExtension, err)
+		}
+		if want := int32(i + 1); got != want {
+			t.Errorf("Expected sequence %d, got %d", want, got)
+		}
+	}
+	if ids.Len() != len(wantData) {
+		t.Errorf("Expected distinct event ids, got %v", ids.List())
+	}
+}
+
+func TestBatchContentType(t *testing.T) {
+	testCases := map[string]struct {
+		contentType string
+		want        []string
+	}{
+		"default": {
+			want: []string{"application/json", "text/plain", "application/json"},
+		},
+		"data content type": {
+			contentType: "application/xml",
+			want:        []string{"application/xml", "text/plain", "application/xml"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Data:            `[{"n":1},{"data":"two","contentType":"text/plain"},{"data":3}]`,
+				DataContentType: tc.contentType,
+				Batch:           true,
+				Client:          ce,
 			}
 
-			got := string(j)
-			if diff := cmp.Diff(tc.want, got); diff != "" {
-				t.Errorf("%s: (-want, +got) = %v", n, diff)
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sent := ce.Sent()
+			if len(sent) != len(tc.want) {
+				t.Fatalf("Expected %d events to be sent, got %d", len(tc.want), len(sent))
+			}
+			for i, event := range sent {
+				if got := event.DataContentType(); got != tc.want[i] {
+					t.Errorf("Expected event %d with content type %q, got %q", i, tc.want[i], got)
+				}
+			}
+			if got := string(sent[1].Data()); got != "two" {
+				t.Errorf("Expected the data of the content type element, got %q", got)
 			}
 		})
 	}
 }
 
-func sinkAccepted(writer http.ResponseWriter, req *http.Request) {
-	writer.WriteHeader(http.StatusOK)
+func TestStartBadBatch(t *testing.T) {
+	a := &pingAdapter{
+		Schedule: "* * * * *",
+		Data:     `{"not":"an array"}`,
+		Batch:    true,
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err == nil {
+		t.Error("Expected an error for non-array batch data")
+	}
 }
 
-func sinkRejected(writer http.ResponseWriter, _ *http.Request) {
-	writer.WriteHeader(http.StatusRequestTimeout)
+func TestSpecVersionDecode(t *testing.T) {
+	for _, value := range []string{"1.0", "0.3"} {
+		var got specVersion
+		if err := got.Decode(value); err != nil || string(got) != value {
+			t.Errorf("Decode(%q) = %v, got %q", value, err, got)
+		}
+	}
+	var got specVersion
+	if err := got.Decode("0.2"); err == nil {
+		t.Error("Expected an error decoding spec version 0.2")
+	}
+}
+
+func TestSpecVersion(t *testing.T) {
+	testCases := map[string]struct {
+		version specVersion
+		want    string
+	}{
+		"default": {
+			want: "1.0",
+		},
+		"1.0": {
+			version: "1.0",
+			want:    "1.0",
+		},
+		"0.3": {
+			version: "0.3",
+			want:    "0.3",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			received := make(chan string, 1)
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received <- r.Header.Get("Ce-Specversion")
+				sinkAccepted(w, r)
+			}))
+			defer sink.Close()
+
+			a := &pingAdapter{
+				Data:        "data",
+				DataSchema:  "https://example.com/schema.json",
+				SpecVersion: tc.version,
+				Client:      newSinkClient(t, sink.URL),
+			}
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := <-received; got != tc.want {
+				t.Errorf("Expected specversion %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestIDStrategy(t *testing.T) {
+	testCases := map[string]struct {
+		strategy idStrategy
+		prefix   string
+		want     []string
+	}{
+		"uuid": {
+			strategy: idStrategyUUID,
+		},
+		"uuid with prefix": {
+			strategy: idStrategyUUID,
+			prefix:   "prod",
+		},
+		"sequence": {
+			strategy: idStrategySequence,
+			want:     []string{"ns-name-1", "ns-name-2", "ns-name-3"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var mu sync.Mutex
+			var ids []string
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				ids = append(ids, r.Header.Get("Ce-Id"))
+				mu.Unlock()
+				sinkAccepted(w, r)
+			}))
+			defer sink.Close()
+
+			a := &pingAdapter{
+				Data:       "data",
+				Name:       "name",
+				Namespace:  "ns",
+				IDStrategy: tc.strategy,
+				IDPrefix:   tc.prefix,
+				Client:     newSinkClient(t, sink.URL),
+			}
+			for i := 0; i < 3; i++ {
+				if err := a.cronTick(context.Background()); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if sets.NewString(ids...).Len() != 3 {
+				t.Errorf("Expected 3 distinct ids, got %v", ids)
+			}
+			if tc.want != nil {
+				if diff := cmp.Diff(tc.want, ids); diff != "" {
+					t.Errorf("unexpected ids (-want, +got) = %v", diff)
+				}
+				return
+			}
+			for _, id := range ids {
+				if tc.prefix != "" {
+					if !strings.HasPrefix(id, tc.prefix+"-") {
+						t.Errorf("Expected an id prefixed with %q, got %q", tc.prefix+"-", id)
+					}
+					id = strings.TrimPrefix(id, tc.prefix+"-")
+				}
+				if _, err := uuid.Parse(id); err != nil {
+					t.Errorf("Expected a UUID id, got %q", id)
+				}
+			}
+		})
+	}
+}
+
+func TestStartRunOnce(t *testing.T) {
+	testCases := map[string]struct {
+		sink  func(http.ResponseWriter, *http.Request)
+		error bool
+	}{
+		"accepted": {
+			sink: sinkAccepted,
+		},
+		"rejected": {
+			sink:  sinkRejected,
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var received int32
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&received, 1)
+				tc.sink(w, r)
+			}))
+			defer s.Close()
+
+			ce := newSinkClient(t, s.URL)
+
+			a := &pingAdapter{
+				Schedule: "0 0 1 1 *", // yearly, never fires during the test
+				Data:     "data",
+				RunOnce:  true,
+				Client:   ce,
+			}
+
+			// Start must return without the stop channel being closed.
+			err := a.Start(context.Background())
+			if tc.error && err == nil {
+				t.Error("expected error, got nil")
+			} else if !tc.error && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if got := atomic.LoadInt32(&received); got < 1 {
+				t.Errorf("Expected the sink to receive the event, got %d requests", got)
+			}
+		})
+	}
+}
+
+func TestStartEndTime(t *testing.T) {
+	t.Run("expired", func(t *testing.T) {
+		a := &pingAdapter{
+			Schedule: "* * * * *",
+			EndTime:  time.Now().Add(-time.Minute),
+		}
+
+		stop := make(chan struct{})
+		close(stop)
+		if err := a.start(stop); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("near future", func(t *testing.T) {
+		ce := adaptertest.NewTestClient()
+
+		a := &pingAdapter{
+			Schedule: "@every 1s",
+			Data:     "data",
+			EndTime:  time.Now().Add(1500 * time.Millisecond),
+			Client:   ce,
+		}
+
+		done := make(chan error)
+		go func() {
+			done <- a.start(make(chan struct{}))
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("start did not return after the end time")
+		}
+
+		n := len(ce.Sent())
+		a.cronTick(context.Background())
+		if got := len(ce.Sent()); got != n {
+			t.Errorf("Expected no event to be sent after the end time, got %d", got-n)
+		}
+	})
+}
+
+func TestDataByNamespace(t *testing.T) {
+	var byNamespace dataByNamespace
+	if err := byNamespace.Decode(`{"team-a":"hello a","team-b":{"hello":"b"}}`); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+
+	testCases := map[string]struct {
+		namespace string
+		data      string
+		want      string
+		error     bool
+	}{
+		"matched namespace": {
+			namespace: "team-a",
+			data:      "fallback",
+			want:      "hello a",
+		},
+		"matched namespace with JSON data": {
+			namespace: "team-b",
+			want:      `{"hello":"b"}`,
+		},
+		"fallback": {
+			namespace: "team-c",
+			data:      "fallback",
+			want:      "fallback",
+		},
+		"no data": {
+			namespace: "team-c",
+			error:     true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			env := &envConfig{
+				EnvConfig:       adapter.EnvConfig{Namespace: tc.namespace, Sink: "http://sink.example.com"},
+				Schedule:        "* * * * *",
+				Data:            tc.data,
+				DataByNamespace: byNamespace,
+			}
+			if err := env.Validate(context.Background()); tc.error != (err != nil) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.error {
+				return
+			}
+			a := NewAdapter(context.Background(), env, adaptertest.NewTestClient()).(*pingAdapter)
+			if a.Data != tc.want {
+				t.Errorf("Expected data %q, got %q", tc.want, a.Data)
+			}
+		})
+	}
+
+	if err := byNamespace.Decode(`["not", "an", "object"]`); err == nil {
+		t.Error("Expected an error decoding a JSON array")
+	}
+}
+
+func TestRandomData(t *testing.T) {
+	testCases := map[string]struct {
+		size            int
+		contentType     string
+		wantContentType string
+	}{
+		"octet stream": {
+			size:            1024,
+			wantContentType: octetStream,
+		},
+		"content type": {
+			size:            16,
+			contentType:     "application/x-protobuf",
+			wantContentType: "application/x-protobuf",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Data:            "ignored",
+				RandomDataSize:  tc.size,
+				DataContentType: tc.contentType,
+				Client:          ce,
+			}
+
+			a.cronTick(context.Background())
+			a.cronTick(context.Background())
+
+			sent := ce.Sent()
+			if len(sent) != 2 {
+				t.Fatalf("Expected 2 events to be sent, got %d", len(sent))
+			}
+			for _, event := range sent {
+				if got := len(event.Data()); got != tc.size {
+					t.Errorf("Expected %d bytes of data, got %d", tc.size, got)
+				}
+				if got := event.DataContentType(); got != tc.wantContentType {
+					t.Errorf("Expected the content type %q, got %q", tc.wantContentType, got)
+				}
+			}
+			if bytes.Equal(sent[0].Data(), sent[1].Data()) {
+				t.Error("Expected random data to differ between ticks")
+			}
+		})
+	}
+}
+
+func TestStartMaxEvents(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Schedule:  "@every 1s",
+		Data:      "data",
+		MaxEvents: 2,
+		Client:    ce,
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- a.start(make(chan struct{}))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("start did not return after the max events were sent")
+	}
+	if got := len(ce.Sent()); got != 2 {
+		t.Errorf("Expected 2 events to be sent, got %d", got)
+	}
+}
+
+func TestMaxEventsFailures(t *testing.T) {
+	ce := &failingClient{TestCloudEventsClient: adaptertest.NewTestClient(), failures: 2}
+	stopped := false
+	a := &pingAdapter{
+		Data:      "data",
+		MaxEvents: 3,
+		Client:    ce,
+		stop:      func() { stopped = true },
+	}
+
+	for i := 0; i < 5; i++ {
+		if stopped {
+			t.Fatalf("Expected the adapter to stop after the 5th tick, stopped after tick %d", i)
+		}
+		a.cronTick(context.Background())
+	}
+	if !stopped {
+		t.Error("Expected the adapter to stop after 3 events were sent")
+	}
+	if got := len(ce.Sent()); got != 3 {
+		t.Errorf("Expected 3 events to be sent, got %d", got)
+	}
+
+	a.cronTick(context.Background())
+	if got := len(ce.Sent()); got != 3 {
+		t.Errorf("Expected no event to be sent after the max events, got %d", got-3)
+	}
+}
+
+func TestStartStartTime(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	a := &pingAdapter{
+		Schedule:  "@every 1s",
+		Data:      "data",
+		StartTime: fakeClock.Now().Add(time.Hour),
+		Client:    ce,
+		clock:     fakeClock,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}); err != nil {
+		t.Fatal("start did not wait for the start time")
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected no event to be sent before the start time, got %d", got)
+	}
+
+	fakeClock.Step(time.Hour)
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(ce.Sent()) > 0, nil
+	}); err != nil {
+		t.Error("Expected an event to be sent after the start time")
+	}
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStartStartupDelay(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	a := &pingAdapter{
+		Schedule:     "@every 1s",
+		Data:         "data",
+		StartupDelay: time.Minute,
+		Client:       ce,
+		clock:        fakeClock,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	}); err != nil {
+		t.Fatal("start did not wait for the startup delay")
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected no event to be sent during the startup delay, got %d", got)
+	}
+
+	fakeClock.Step(time.Minute)
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(ce.Sent()) > 0, nil
+	}); err != nil {
+		t.Error("Expected an event to be sent after the startup delay")
+	}
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStartStartupDelayStopped(t *testing.T) {
+	a := &pingAdapter{
+		Schedule:     "@every 1s",
+		Data:         "data",
+		StartupDelay: time.Hour,
+		Client:       adaptertest.NewTestClient(),
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSkipIfRunning(t *testing.T) {
+	ce := adaptertest.NewTestClientWithDelay(500 * time.Millisecond)
+
+	a := &pingAdapter{
+		Schedule:      "* * * * *",
+		Data:          "data",
+		SkipIfRunning: true,
+		Client:        ce,
+	}
+
+	ctx := context.Background()
+	job := a.skipIfRunning(ctx, cron.FuncJob(func() { _ = a.cronTick(ctx) }))
+
+	done := make(chan struct{})
+	go func() {
+		job.Run()
+		close(done)
+	}()
+
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&a.running) == 1, nil
+	}); err != nil {
+		t.Fatal("first tick did not start")
+	}
+
+	job.Run() // overlapping tick, must be skipped.
+	<-done
+
+	if got := len(ce.Sent()); got != 1 {
+		t.Errorf("Expected 1 event to be sent, got %d", got)
+	}
+
+	job.Run() // no longer overlapping.
+	if got := len(ce.Sent()); got != 2 {
+		t.Errorf("Expected 2 events to be sent, got %d", got)
+	}
+}
+
+func TestStartBadRetry(t *testing.T) {
+	testCases := map[string]struct {
+		count   int
+		backoff time.Duration
+	}{
+		"negative count": {
+			count:   -1,
+			backoff: time.Second,
+		},
+		"zero backoff": {
+			count: 3,
+		},
+		"negative backoff": {
+			count:   3,
+			backoff: -time.Second,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{
+				Schedule:     "* * * * *",
+				RetryCount:   tc.count,
+				RetryBackoff: tc.backoff,
+			}
+
+			stop := make(chan struct{})
+			close(stop)
+			if err := a.start(stop); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	ce := &contextClient{TestCloudEventsClient: adaptertest.NewTestClient()}
+
+	a := &pingAdapter{
+		Data:         "data",
+		RetryCount:   7,
+		RetryBackoff: 2 * time.Second,
+		Client:       ce,
+	}
+
+	a.cronTick(context.Background())
+
+	want := &cecontext.RetryParams{
+		Strategy: cecontext.BackoffStrategyExponential,
+		MaxTries: 7,
+		Period:   2 * time.Second,
+	}
+	if diff := cmp.Diff(want, cecontext.RetriesFrom(ce.ctx)); diff != "" {
+		t.Errorf("unexpected retry params (-want, +got) = %v", diff)
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+	defer close(release)
+
+	a := &pingAdapter{
+		Data:        "data",
+		SendTimeout: 100 * time.Millisecond,
+		Client:      newSinkClient(t, s.URL),
+	}
+
+	start := time.Now()
+	err := a.cronTick(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("Expected the send to time out, took %v", elapsed)
+	}
+}
+
+func TestDataFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ping")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(path, []byte(`{"body":"file"}`), 0644); err != nil {
+		t.Fatalf("failed to write data file: %v", err)
+	}
+
+	testCases := map[string]struct {
+		data         string
+		dataFromFile string
+		error        bool
+	}{
+		"file": {
+			dataFromFile: path,
+		},
+		"missing file": {
+			dataFromFile: filepath.Join(dir, "missing"),
+			error:        true,
+		},
+		"data and file": {
+			data:         `{"body":"data"}`,
+			dataFromFile: path,
+			error:        true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+
+			a := &pingAdapter{
+				Schedule:     "* * * * *",
+				Data:         tc.data,
+				DataFromFile: tc.dataFromFile,
+				Client:       ce,
+			}
+
+			stop := make(chan struct{})
+			close(stop)
+			err := a.start(stop)
+			if tc.error {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			a.cronTick(context.Background())
+			validateSent(t, ce, `{"body":"file"}`)
+		})
+	}
+}
+
+func TestDataReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ping")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(path, []byte(`{"body":"first"}`), 0644); err != nil {
+		t.Fatalf("failed to write data file: %v", err)
+	}
+
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		DataFromFile: path,
+		DataReload:   true,
+		Client:       ce,
+	}
+
+	a.cronTick(context.Background())
+	if err := ioutil.WriteFile(path, []byte(`{"body":"second"}`), 0644); err != nil {
+		t.Fatalf("failed to write data file: %v", err)
+	}
+	a.cronTick(context.Background())
+
+	sent := ce.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 events to be sent, got %d", len(sent))
+	}
+	if got, want := string(sent[1].Data()), `{"body":"second"}`; got != want {
+		t.Errorf("Expected %q event to be sent, got %q", want, got)
+	}
+}
+
+func TestDataContentType(t *testing.T) {
+	testCases := map[string]struct {
+		data            string
+		dataContentType string
+		wantData        string
+		wantContentType string
+	}{
+		"default json": {
+			data:            "Hello, World!",
+			wantData:        `{"body":"Hello, World!"}`,
+			wantContentType: cloudevents.ApplicationJSON,
+		},
+		"text passthrough": {
+			data:            `{"not":"wrapped"}`,
+			dataContentType: cloudevents.TextPlain,
+			wantData:        `{"not":"wrapped"}`,
+			wantContentType: cloudevents.TextPlain,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+
+			a := &pingAdapter{
+				Data:            tc.data,
+				DataContentType: tc.dataContentType,
+				Client:          ce,
+			}
+
+			a.cronTick(context.Background())
+			validateSent(t, ce, tc.wantData)
+			if got := ce.Sent()[0].DataContentType(); got != tc.wantContentType {
+				t.Errorf("Expected content type %q, got %q", tc.wantContentType, got)
+			}
+		})
+	}
+}
+
+func TestDataBase64(t *testing.T) {
+	t.Run("decoded", func(t *testing.T) {
+		ce := adaptertest.NewTestClient()
+		want := []byte{0x08, 0x96, 0x01, 0xff}
+
+		a := &pingAdapter{
+			Schedule:   "* * * * *",
+			Data:       base64.StdEncoding.EncodeToString(want),
+			DataBase64: true,
+			Client:     ce,
+		}
+
+		stop := make(chan struct{})
+		close(stop)
+		if err := a.start(stop); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		a.cronTick(context.Background())
+		sent := ce.Sent()
+		if len(sent) != 1 {
+			t.Fatalf("Expected 1 event to be sent, got %d", len(sent))
+		}
+		if diff := cmp.Diff(want, sent[0].Data()); diff != "" {
+			t.Errorf("unexpected data (-want, +got) = %v", diff)
+		}
+		if got := sent[0].DataContentType(); got != "application/octet-stream" {
+			t.Errorf("Expected content type application/octet-stream, got %q", got)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		a := &pingAdapter{
+			Schedule:   "* * * * *",
+			Data:       "not base64!",
+			DataBase64: true,
+		}
+
+		stop := make(chan struct{})
+		close(stop)
+		if err := a.start(stop); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestSubject(t *testing.T) {
+	testCases := map[string]struct {
+		subject string
+		want    string
+	}{
+		"unset": {},
+		"literal": {
+			subject: "heartbeat",
+			want:    "heartbeat",
+		},
+		"templated": {
+			subject: "{namespace}/{name}",
+			want:    "ns/name",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+
+			a := &pingAdapter{
+				Data:      "data",
+				Name:      "name",
+				Namespace: "ns",
+				Subject:   tc.subject,
+				Client:    ce,
+			}
+
+			a.cronTick(context.Background())
+			sent := ce.Sent()
+			if len(sent) != 1 {
+				t.Fatalf("Expected 1 event to be sent, got %d", len(sent))
+			}
+			if got := sent[0].Subject(); got != tc.want {
+				t.Errorf("Expected subject %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPartitionKey(t *testing.T) {
+	testCases := map[string]struct {
+		key  string
+		want interface{}
+	}{
+		"unset": {},
+		"literal": {
+			key:  "pings",
+			want: "pings",
+		},
+		"templated": {
+			key:  "{namespace}/{name}",
+			want: "ns/name",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+
+			a := &pingAdapter{
+				Data:         "data",
+				Name:         "name",
+				Namespace:    "ns",
+				PartitionKey: tc.key,
+				Client:       ce,
+			}
+
+			a.cronTick(context.Background())
+			sent := ce.Sent()
+			if len(sent) != 1 {
+				t.Fatalf("Expected 1 event to be sent, got %d", len(sent))
+			}
+			if got := sent[0].Extensions()[partitionKeyExtension]; got != tc.want {
+				t.Errorf("Expected partition key %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDataSchema(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+
+	a := &pingAdapter{
+		Data:       "data",
+		DataSchema: "https://schemas.example.com/ping/v1.json",
+		Client:     ce,
+	}
+
+	a.cronTick(context.Background())
+	sent := ce.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 event to be sent, got %d", len(sent))
+	}
+	if got := sent[0].DataSchema(); got != a.DataSchema {
+		t.Errorf("Expected dataschema %q, got %q", a.DataSchema, got)
+	}
+}
+
+func TestStartBadDataSchema(t *testing.T) {
+	a := &pingAdapter{
+		Schedule:   "* * * * *",
+		DataSchema: "schemas/ping.json",
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err == nil {
+		t.Error("Expected an error for a relative data schema")
+	}
+}
+
+func TestExtensionsDecode(t *testing.T) {
+	testCases := map[string]struct {
+		value string
+		want  extensions
+		error bool
+	}{
+		"valid": {
+			value: `{"environment":"prod","region":"eu"}`,
+			want:  extensions{"environment": "prod", "region": "eu"},
+		},
+		"invalid json": {
+			value: `{"environment":`,
+			error: true,
+		},
+		"not strings": {
+			value: `{"count":1}`,
+			error: true,
+		},
+		"uppercase name": {
+			value: `{"Environment":"prod"}`,
+			error: true,
+		},
+		"non alphanumeric name": {
+			value: `{"env-name":"prod"}`,
+			error: true,
+		},
+		"reserved id": {
+			value: `{"id":"1"}`,
+			error: true,
+		},
+		"reserved source": {
+			value: `{"source":"/here"}`,
+			error: true,
+		},
+		"reserved type": {
+			value: `{"type":"ping"}`,
+			error: true,
+		},
+		"adapter correlationid": {
+			value: `{"correlationid":"1"}`,
+			error: true,
+		},
+		"adapter sequence": {
+			value: `{"sequence":"1"}`,
+			error: true,
+		},
+		"adapter traceparent": {
+			value: `{"traceparent":"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}`,
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got extensions
+			err := got.Decode(tc.value)
+			if tc.error {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected extensions (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestExtensions(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+
+	a := &pingAdapter{
+		Data:       "data",
+		Extensions: map[string]string{"environment": "prod", "region": "eu"},
+		Client:     ce,
+	}
+
+	a.cronTick(context.Background())
+	sent := ce.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 event to be sent, got %d", len(sent))
+	}
+	for name, want := range a.Extensions {
+		if got := sent[0].Extensions()[name]; got != want {
+			t.Errorf("Expected extension %s=%q, got %v", name, want, got)
+		}
+	}
+}
+
+func TestStartDrain(t *testing.T) {
+	testCases := map[string]struct {
+		drainTimeout time.Duration
+		wantSent     int
+	}{
+		"completed": {
+			drainTimeout: 5 * time.Second,
+			wantSent:     1,
+		},
+		"abandoned": {
+			drainTimeout: 100 * time.Millisecond,
+			wantSent:     0,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClientWithDelay(1500 * time.Millisecond)
+
+			a := &pingAdapter{
+				Schedule:      "@every 1s",
+				Data:          "data",
+				SkipIfRunning: true,
+				DrainTimeout:  tc.drainTimeout,
+				Client:        ce,
+			}
+
+			stop := make(chan struct{})
+			done := make(chan error)
+			go func() {
+				done <- a.start(stop)
+			}()
+
+			if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+				return atomic.LoadInt32(&a.inflightCount) > 0, nil
+			}); err != nil {
+				t.Fatal("no send in flight")
+			}
+
+			close(stop)
+			if err := <-done; err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if got := len(ce.Sent()); got != tc.wantSent {
+				t.Errorf("Expected %d event sent at shutdown, got %d", tc.wantSent, got)
+			}
+		})
+	}
+}
+
+func TestEncodingDecode(t *testing.T) {
+	testCases := map[string]struct {
+		value string
+		want  encoding
+		error bool
+	}{
+		"default": {
+			value: "",
+			want:  "",
+		},
+		"binary": {
+			value: "binary",
+			want:  encodingBinary,
+		},
+		"structured": {
+			value: "structured",
+			want:  encodingStructured,
+		},
+		"invalid": {
+			value: "batched",
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got encoding
+			err := got.Decode(tc.value)
+			if tc.error != (err != nil) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected encoding %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	testCases := map[string]struct {
+		encoding    encoding
+		contentType string
+		specVersion string
+	}{
+		"default": {
+			contentType: "application/json",
+			specVersion: "1.0",
+		},
+		"binary": {
+			encoding:    encodingBinary,
+			contentType: "application/json",
+			specVersion: "1.0",
+		},
+		"structured": {
+			encoding:    encodingStructured,
+			contentType: "application/cloudevents+json",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var contentType, specVersion string
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				specVersion = r.Header.Get("Ce-Specversion")
+				sinkAccepted(w, r)
+			}))
+			defer sink.Close()
+
+			a := &pingAdapter{
+				Data:     "data",
+				Encoding: tc.encoding,
+				Client:   newSinkClient(t, sink.URL),
+			}
+
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if contentType != tc.contentType {
+				t.Errorf("Expected content type %q, got %q", tc.contentType, contentType)
+			}
+			if specVersion != tc.specVersion {
+				t.Errorf("Expected ce-specversion header %q, got %q", tc.specVersion, specVersion)
+			}
+		})
+	}
+}
+
+func TestPostMessage_ServeHTTP(t *testing.T) {
+	testCases := map[string]struct {
+		sink  func(http.ResponseWriter, *http.Request)
+		data  string
+		error bool
+	}{
+		"happy": {
+			sink: sinkAccepted,
+			data: `{"body":"data"}`,
+		},
+		"rejected": {
+			sink:  sinkRejected,
+			data:  `{"body":"data"}`,
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+
+			ce := adaptertest.NewTestClient()
+
+			a := &pingAdapter{
+				Data:   "data",
+				Client: ce,
+			}
+
+			a.cronTick(context.Background())
+			validateSent(t, ce, tc.data)
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	testCases := map[string]struct {
+		body string
+		want string
+	}{
+		"json simple": {
+			body: `{"message": "Hello world!"}`,
+			want: `{"message":"Hello world!"}`,
+		},
+		"json complex": {
+			body: `{"message": "Hello world!","extra":{"a":"sub", "b":[1,2,3]}}`,
+			want: `{"extra":{"a":"sub","b":[1,2,3]},"message":"Hello world!"}`,
+		},
+		"string": {
+			body: "Hello, World!",
+			want: `{"body":"Hello, World!"}`,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+
+			m := (&pingAdapter{}).message(context.Background(), tc.body, templateData{})
+
+			j, err := json.Marshal(m)
+			if err != nil {
+				t.Errorf("failed to marshel message: %v", err)
+			}
+
+			got := string(j)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("%s: (-want, +got) = %v", n, diff)
+			}
+		})
+	}
+}
+
+func TestYAMLMessage(t *testing.T) {
+	testCases := map[string]struct {
+		body string
+		want string
+	}{
+		"yaml mapping": {
+			body: "message: Hello world!\nextra:\n  a: sub\n  b: [1, 2, 3]\n",
+			want: `{"extra":{"a":"sub","b":[1,2,3]},"message":"Hello world!"}`,
+		},
+		"json": {
+			body: `{"message": "Hello world!"}`,
+			want: `{"message":"Hello world!"}`,
+		},
+		"scalar": {
+			body: "Hello, World!",
+			want: `{"body":"Hello, World!"}`,
+		},
+		"malformed": {
+			body: "message: [unclosed",
+			want: `{"body":"message: [unclosed"}`,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+
+			a := &pingAdapter{
+				Data:       tc.body,
+				DataFormat: dataFormatYAML,
+				Client:     ce,
+			}
+
+			a.cronTick(context.Background())
+			validateSent(t, ce, tc.want)
+		})
+	}
+}
+
+func TestMessageFieldsDecode(t *testing.T) {
+	testCases := map[string]struct {
+		value string
+		want  messageFields
+		error bool
+	}{
+		"empty": {
+			value: "",
+		},
+		"both": {
+			value: "time, sequence",
+			want:  messageFields{"time", "sequence"},
+		},
+		"unknown": {
+			value: "time,host",
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got messageFields
+			err := got.Decode(tc.value)
+			if tc.error != (err != nil) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected fields (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMessageFields(t *testing.T) {
+	tick := templateData{
+		Time:     time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC),
+		Sequence: 7,
+	}
+	testCases := map[string]struct {
+		fields []string
+		body   string
+		want   string
+	}{
+		"object merged": {
+			fields: []string{"time", "sequence"},
+			body:   `{"message": "Hello world!"}`,
+			want:   `{"message":"Hello world!","sequence":7,"time":"2020-06-01T12:30:00Z"}`,
+		},
+		"object field kept": {
+			fields: []string{"sequence"},
+			body:   `{"sequence": "mine"}`,
+			want:   `{"sequence":"mine"}`,
+		},
+		"string wrapped": {
+			fields: []string{"time", "sequence"},
+			body:   "Hello, World!",
+			want:   `{"body":"Hello, World!"}`,
+		},
+		"no fields": {
+			body: `{"message": "Hello world!"}`,
+			want: `{"message":"Hello world!"}`,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{MessageFields: tc.fields}
+
+			j, err := json.Marshal(a.message(context.Background(), tc.body, tick))
+			if err != nil {
+				t.Fatalf("failed to marshal message: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, string(j)); diff != "" {
+				t.Errorf("unexpected message (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMessageRawData(t *testing.T) {
+	tick := templateData{Time: time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC), Sequence: 1}
+	testCases := map[string]struct {
+		raw  bool
+		body string
+		want string
+	}{
+		"json object": {
+			body: `{"message": "Hello world!"}`,
+			want: `{"message":"Hello world!"}`,
+		},
+		"raw json object": {
+			raw:  true,
+			body: `{"message": "Hello world!"}`,
+			want: `{"body":"{\"message\": \"Hello world!\"}"}`,
+		},
+		"raw string": {
+			raw:  true,
+			body: "Hello, World!",
+			want: `{"body":"Hello, World!"}`,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{RawData: tc.raw}
+
+			j, err := json.Marshal(a.message(context.Background(), tc.body, tick))
+			if err != nil {
+				t.Fatalf("failed to marshal message: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, string(j)); diff != "" {
+				t.Errorf("unexpected message (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMessageWrap(t *testing.T) {
+	tick := templateData{
+		Time:      time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC),
+		Name:      "test-name",
+		Namespace: "test-ns",
+	}
+	testCases := map[string]struct {
+		field string
+		meta  bool
+		want  string
+	}{
+		"default": {
+			want: `{"body":"Hello, World!"}`,
+		},
+		"body field": {
+			field: "body",
+			want:  `{"body":"Hello, World!"}`,
+		},
+		"renamed field": {
+			field: "message",
+			want:  `{"message":"Hello, World!"}`,
+		},
+		"metadata": {
+			meta: true,
+			want: `{"body":"Hello, World!","name":"test-name","namespace":"test-ns","time":"2020-06-01T12:30:00Z"}`,
+		},
+		"renamed field and metadata": {
+			field: "text",
+			meta:  true,
+			want:  `{"name":"test-name","namespace":"test-ns","text":"Hello, World!","time":"2020-06-01T12:30:00Z"}`,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{MessageWrapField: tc.field, MessageWrapMeta: tc.meta}
+
+			j, err := json.Marshal(a.message(context.Background(), "Hello, World!", tick))
+			if err != nil {
+				t.Fatalf("failed to marshal message: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, string(j)); diff != "" {
+				t.Errorf("unexpected message (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMessageWrapMetaField(t *testing.T) {
+	a := &pingAdapter{Schedule: "* * * * *", Data: "data", MessageWrapField: "time", MessageWrapMeta: true}
+	if _, _, err := a.prepare(context.Background()); err == nil {
+		t.Error("Expected an error wrapping the data in a field of the metadata")
+	}
+}
+
+func sinkAccepted(writer http.ResponseWriter, req *http.Request) {
+	writer.WriteHeader(http.StatusOK)
+}
+
+func sinkRejected(writer http.ResponseWriter, _ *http.Request) {
+	writer.WriteHeader(http.StatusRequestTimeout)
+}
+
+// contextClient records the context of the last sent event.
+type contextClient struct {
+	*adaptertest.TestCloudEventsClient
+	ctx context.Context
+}
+
+func (c *contextClient) Send(ctx context.Context, out event.Event) protocol.Result {
+	c.ctx = ctx
+	return c.TestCloudEventsClient.Send(ctx, out)
+}
+
+// failingClient fails the first failures sends.
+type failingClient struct {
+	*adaptertest.TestCloudEventsClient
+	failures int
+}
+
+func (c *failingClient) Send(ctx context.Context, out event.Event) protocol.Result {
+	if c.failures > 0 {
+		c.failures--
+		return cehttp.NewResult(http.StatusServiceUnavailable, "unavailable")
+	}
+	return c.TestCloudEventsClient.Send(ctx, out)
+}
+
+func newSinkClient(t *testing.T, target string) cloudevents.Client {
+	p, err := cloudevents.NewHTTP(cloudevents.WithTarget(target))
+	if err != nil {
+		t.Fatalf("failed to create protocol: %v", err)
+	}
+	ce, err := cloudevents.NewClient(p, cloudevents.WithUUIDs(), cloudevents.WithTimeNow())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return ce
+}
+
+// newTransportClient returns a client sending to target through rt. The
+// protocol has its own http.Client, rt would otherwise be set on the
+// http.DefaultClient shared with the other tests.
+func newTransportClient(t *testing.T, target string, rt http.RoundTripper) cloudevents.Client {
+	p, err := cloudevents.NewHTTP(cloudevents.WithTarget(target), cehttp.WithClient(http.Client{}), cloudevents.WithRoundTripper(rt))
+	if err != nil {
+		t.Fatalf("failed to create protocol: %v", err)
+	}
+	ce, err := cloudevents.NewClient(p, cloudevents.WithUUIDs(), cloudevents.WithTimeNow())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return ce
 }
 
 func validateSent(t *testing.T, ce *adaptertest.TestCloudEventsClient, wantData string) {
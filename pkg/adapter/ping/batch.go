@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// sendBatch sends the events of a batch one after the other, and returns
+// how many failed. Once stop is closed on shutdown, the events not sent
+// yet are flushed for up to the DrainTimeout. The events whose send has
+// not started then are dropped.
+func (a *pingAdapter) sendBatch(ctx context.Context, stop <-chan struct{}, events []cloudevents.Event) int {
+	failed := 0
+	var deadline time.Time
+	for i, event := range events {
+		if a.maxEventsReached() {
+			break
+		}
+		if deadline.IsZero() && isClosed(stop) {
+			deadline = a.now().Add(a.DrainTimeout)
+			logging.FromContext(ctx).Infow("ping shutting down, flushing the batch",
+				zap.Int("unsent", len(events)-i), zap.Duration("timeout", a.DrainTimeout))
+		}
+		if !deadline.IsZero() && !a.now().Before(deadline) {
+			a.dropBatch(ctx, len(events)-i)
+			break
+		}
+		if err := a.sendCounted(ctx, event); err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// dropBatch records the dropped events of a batch which could not be
+// flushed on shutdown.
+func (a *pingAdapter) dropBatch(ctx context.Context, dropped int) {
+	logging.FromContext(ctx).Warnw("ping drain timeout expired, dropping the unsent batched cloudevents",
+		zap.Int("dropped", dropped))
+	for i := 0; i < dropped; i++ {
+		if err := a.reportDropped(); err != nil {
+			logging.FromContext(ctx).Errorw("failed to record the dropped event", zap.Error(err))
+			return
+		}
+	}
+}
+
+// isClosed reports whether ch is closed. A nil ch is never closed.
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestBatchFlush(t *testing.T) {
+	testCases := map[string]struct {
+		// expire steps the clock past the drain timeout while the second
+		// event is sent.
+		expire  bool
+		want    int
+		dropped int64
+	}{
+		"flushed": {
+			want: 4,
+		},
+		"drain timeout expired": {
+			expire:  true,
+			want:    2,
+			dropped: 2,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			resetMetrics()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			fakeClock := clock.NewFakeClock(time.Date(2020, 8, 3, 0, 0, 0, 0, time.UTC))
+
+			var mu sync.Mutex
+			var received int
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				received++
+				switch received {
+				case 1:
+					// Shut down while the batch is being sent.
+					cancel()
+				case 2:
+					if tc.expire {
+						fakeClock.Step(11 * time.Second)
+					}
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer sink.Close()
+
+			a := &pingAdapter{
+				Name:         "testname",
+				Namespace:    "testns",
+				Data:         `["a","b","c","d"]`,
+				Batch:        true,
+				DrainTimeout: 10 * time.Second,
+				Client:       newSinkClient(t, sink.URL),
+				clock:        fakeClock,
+			}
+			if err := a.cronTick(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if received != tc.want {
+				t.Errorf("Expected %d events to be sent, got %d", tc.want, received)
+			}
+			if tc.dropped > 0 {
+				metricstest.CheckCountData(t, "events_dropped_total", map[string]string{
+					metricskey.LabelNamespaceName: "testns",
+					metricskey.LabelName:          "testname",
+				}, tc.dropped)
+			} else {
+				metricstest.CheckStatsNotReported(t, "events_dropped_total")
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// weekdays are the abbreviated day names of the blackout windows and the
+// business hours.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// blackoutWindow is a recurring range of the day, from start included to
+// end excluded, on days, or every day when no days are set. A window
+// whose end is before its start crosses midnight, and its days are the
+// days it starts on.
+type blackoutWindow struct {
+	days       map[time.Weekday]bool
+	start, end time.Duration
+}
+
+// blackoutWindows are the windows ticks skip sending in, decoded from a
+// semicolon-separated list of windows such as "00:00-02:00" or
+// "Mon-Fri 22:00-06:00" or "Sat,Sun 10:00-12:00".
+type blackoutWindows []blackoutWindow
+
+// Decode implements envconfig.Decoder
+func (w *blackoutWindows) Decode(value string) error {
+	var windows blackoutWindows
+	for _, spec := range strings.Split(value, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		window, err := parseBlackoutWindow(spec)
+		if err != nil {
+			return fmt.Errorf("invalid blackout window %q: %v", strings.TrimSpace(spec), err)
+		}
+		windows = append(windows, window)
+	}
+	*w = windows
+	return nil
+}
+
+// parseBlackoutWindow parses a window of optional days and a time range.
+func parseBlackoutWindow(spec string) (blackoutWindow, error) {
+	var window blackoutWindow
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 1:
+	case 2:
+		days, err := parseWeekdays(fields[0])
+		if err != nil {
+			return window, err
+		}
+		window.days = days
+	default:
+		return window, fmt.Errorf("must be [days] HH:MM-HH:MM")
+	}
+
+	bounds := strings.Split(fields[len(fields)-1], "-")
+	if len(bounds) != 2 {
+		return window, fmt.Errorf("must be [days] HH:MM-HH:MM")
+	}
+	var err error
+	if window.start, err = parseTimeOfDay(bounds[0]); err != nil {
+		return window, err
+	}
+	if window.end, err = parseTimeOfDay(bounds[1]); err != nil {
+		return window, err
+	}
+	if window.start == window.end {
+		return window, fmt.Errorf("must not be empty")
+	}
+	return window, nil
+}
+
+// parseWeekdays parses a comma-separated list of days and ranges of days
+// such as Mon-Fri.
+func parseWeekdays(value string) (map[time.Weekday]bool, error) {
+	days := map[time.Weekday]bool{}
+	for _, r := range strings.Split(value, ",") {
+		bounds := strings.Split(r, "-")
+		if len(bounds) > 2 {
+			return nil, fmt.Errorf("invalid days %q", r)
+		}
+		var first, last time.Weekday
+		var ok bool
+		if first, ok = weekdays[strings.ToLower(bounds[0])]; !ok {
+			return nil, fmt.Errorf("invalid day %q: must be one of Mon, Tue, Wed, Thu, Fri, Sat or Sun", bounds[0])
+		}
+		last = first
+		if len(bounds) == 2 {
+			if last, ok = weekdays[strings.ToLower(bounds[1])]; !ok {
+				return nil, fmt.Errorf("invalid day %q: must be one of Mon, Tue, Wed, Thu, Fri, Sat or Sun", bounds[1])
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseTimeOfDay parses HH:MM into the duration since midnight.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: must be HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// onDay reports whether the window recurs on day.
+func (w blackoutWindow) onDay(day time.Weekday) bool {
+	return len(w.days) == 0 || w.days[day]
+}
+
+// contains reports whether t is in the window.
+func (w blackoutWindow) contains(t time.Time) bool {
+	tod := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+	if w.start < w.end {
+		return w.onDay(t.Weekday()) && tod >= w.start && tod < w.end
+	}
+	// The window crosses midnight, its morning part belongs to the day
+	// before.
+	return (w.onDay(t.Weekday()) && tod >= w.start) || (w.onDay((t.Weekday()+6)%7) && tod < w.end)
+}
+
+// inBlackout reports whether t, in the time zone of the schedule, is in
+// one of the Blackout windows.
+func (a *pingAdapter) inBlackout(t time.Time) bool {
+	if len(a.Blackout) == 0 {
+		return false
+	}
+	if a.Timezone != "" {
+		if loc, err := time.LoadLocation(a.Timezone); err == nil {
+			t = t.In(loc)
+		}
+	}
+	for _, w := range a.Blackout {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
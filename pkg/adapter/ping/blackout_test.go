@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestBlackoutWindowsDecode(t *testing.T) {
+	var got blackoutWindows
+	if err := got.Decode("00:00-02:00; Mon-Fri 22:00-06:00;Sat,Sun 10:00-12:30"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(got))
+	}
+	if w := got[2]; len(w.days) != 2 || !w.days[time.Saturday] || !w.days[time.Sunday] || w.end != 12*time.Hour+30*time.Minute {
+		t.Errorf("Unexpected window %+v", w)
+	}
+
+	for _, value := range []string{"00:00", "25:00-26:00", "01:00-01:00", "Someday 00:00-01:00", "Mon 00:00-01:00 extra"} {
+		if err := got.Decode(value); err == nil {
+			t.Errorf("Expected an error decoding %q", value)
+		}
+	}
+}
+
+func TestBlackout(t *testing.T) {
+	// 2020-08-03 is a Monday.
+	testCases := map[string]struct {
+		blackout string
+		now      time.Time
+		want     int
+	}{
+		"in window": {
+			blackout: "00:00-02:00",
+			now:      time.Date(2020, 8, 3, 1, 0, 0, 0, time.UTC),
+			want:     0,
+		},
+		"out of window": {
+			blackout: "00:00-02:00",
+			now:      time.Date(2020, 8, 3, 2, 0, 0, 0, time.UTC),
+			want:     1,
+		},
+		"other day": {
+			blackout: "Tue 00:00-02:00",
+			now:      time.Date(2020, 8, 3, 1, 0, 0, 0, time.UTC),
+			want:     1,
+		},
+		"crossing midnight, evening": {
+			blackout: "Mon 22:00-02:00",
+			now:      time.Date(2020, 8, 3, 23, 0, 0, 0, time.UTC),
+			want:     0,
+		},
+		"crossing midnight, morning": {
+			blackout: "Sun 22:00-02:00",
+			now:      time.Date(2020, 8, 3, 1, 0, 0, 0, time.UTC),
+			want:     0,
+		},
+		"crossing midnight, morning of the start day": {
+			blackout: "Mon 22:00-02:00",
+			now:      time.Date(2020, 8, 3, 1, 0, 0, 0, time.UTC),
+			want:     1,
+		},
+		"crossing midnight, day of the week range": {
+			blackout: "Fri-Mon 22:00-02:00",
+			now:      time.Date(2020, 8, 4, 1, 0, 0, 0, time.UTC),
+			want:     0,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var blackout blackoutWindows
+			if err := blackout.Decode(tc.blackout); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Data:     "data",
+				Blackout: blackout,
+				Client:   ce,
+				clock:    clock.NewFakeClock(tc.now),
+			}
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := len(ce.Sent()); got != tc.want {
+				t.Errorf("Expected %d events to be sent, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBlackoutTimezone(t *testing.T) {
+	var blackout blackoutWindows
+	if err := blackout.Decode("00:00-02:00"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a := &pingAdapter{Blackout: blackout, Timezone: "America/New_York"}
+	if a.inBlackout(time.Date(2020, 8, 3, 1, 0, 0, 0, time.UTC)) {
+		t.Error("Expected 01:00 UTC out of the window in New York")
+	}
+	if !a.inBlackout(time.Date(2020, 8, 3, 5, 0, 0, 0, time.UTC)) {
+		t.Error("Expected 05:00 UTC in the window in New York")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// errCircuitOpen is returned by the ticks skipped while the circuit
+// breaker is open.
+var errCircuitOpen = errors.New("circuit breaker open, skipping tick")
+
+// breakerState is the state of a circuitBreaker.
+type breakerState int
+
+const (
+	// breakerClosed lets all the ticks through.
+	breakerClosed breakerState = iota
+	// breakerOpen skips the ticks until the cooldown is over.
+	breakerOpen
+	// breakerHalfOpen lets a single probe tick through, whose outcome
+	// closes or opens the circuit again.
+	breakerHalfOpen
+)
+
+// circuitBreaker skips the ticks after consecutive send failures. Its zero
+// value is a closed circuit.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// allow reports whether a tick at now can send, moving an open circuit
+// whose cooldown is over to half-open for a single probe.
+func (b *circuitBreaker) allow(now time.Time, cooldown time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen, breakerHalfOpen:
+		// A probe which did not send within the cooldown, failing before
+		// the send, is given up.
+		if now.Sub(b.openedAt) < cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.openedAt = now
+		return true
+	default:
+		return true
+	}
+}
+
+// record records the outcome of a send at now, opening the circuit after
+// threshold consecutive failures, or on a failed probe.
+func (b *circuitBreaker) record(sent bool, now time.Time, threshold int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sent {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= threshold {
+		b.state = breakerOpen
+		b.openedAt = now
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC))
+	ce := &failingClient{TestCloudEventsClient: adaptertest.NewTestClient(), failures: 3}
+	a := &pingAdapter{
+		Data:                    "data",
+		CircuitBreakerThreshold: 2,
+		CircuitBreakerCooldown:  time.Minute,
+		Client:                  ce,
+		clock:                   fakeClock,
+	}
+
+	// tick checks the outcome of a tick: sent, failed or skipped.
+	tick := func(want string) {
+		t.Helper()
+		attempts, sent := ce.failures, len(ce.Sent())
+		err := a.cronTick(context.Background())
+		switch want {
+		case "sent":
+			if err != nil || len(ce.Sent()) != sent+1 {
+				t.Errorf("Expected the tick to send, got %v", err)
+			}
+		case "failed":
+			if err == nil || err == errCircuitOpen || ce.failures != attempts-1 {
+				t.Errorf("Expected the tick to fail, got %v", err)
+			}
+		case "skipped":
+			if err != errCircuitOpen || ce.failures != attempts || len(ce.Sent()) != sent {
+				t.Errorf("Expected the tick to be skipped, got %v", err)
+			}
+		}
+	}
+
+	// Two consecutive failures open the circuit.
+	tick("failed")
+	tick("failed")
+	tick("skipped")
+
+	// The failing probe opens the circuit again.
+	fakeClock.Step(time.Minute)
+	tick("failed")
+	tick("skipped")
+
+	// The successful probe closes the circuit.
+	fakeClock.Step(time.Minute)
+	tick("sent")
+	tick("sent")
+}
+
+func TestCircuitBreakerLostProbe(t *testing.T) {
+	now := time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC)
+	var b circuitBreaker
+	b.record(false, now, 1)
+
+	now = now.Add(time.Minute)
+	if !b.allow(now, time.Minute) {
+		t.Fatal("Expected a probe to be allowed after the cooldown")
+	}
+	if b.allow(now, time.Minute) {
+		t.Error("Expected a single probe while half-open")
+	}
+	if !b.allow(now.Add(time.Minute), time.Minute) {
+		t.Error("Expected a new probe when the previous one did not send within the cooldown")
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// businessHours is the recurring window the ticks only send in, decoded
+// from optional days, a time range and an optional time zone such as
+// "Mon-Fri 09:00-17:00 America/Chicago". Without a time zone, the window
+// is in the time zone of the schedule.
+type businessHours struct {
+	spec   string
+	window blackoutWindow
+	loc    *time.Location
+}
+
+// Decode implements envconfig.Decoder
+func (h *businessHours) Decode(value string) error {
+	spec := strings.TrimSpace(value)
+	if spec == "" {
+		*h = businessHours{}
+		return nil
+	}
+
+	hours := businessHours{spec: spec}
+	fields := strings.Fields(spec)
+	// The time range is the only field with a colon.
+	if last := fields[len(fields)-1]; len(fields) > 1 && !strings.Contains(last, ":") {
+		loc, err := time.LoadLocation(last)
+		if err != nil {
+			return fmt.Errorf("invalid business hours %q: %v", spec, err)
+		}
+		hours.loc = loc
+		fields = fields[:len(fields)-1]
+	}
+	window, err := parseBlackoutWindow(strings.Join(fields, " "))
+	if err != nil {
+		return fmt.Errorf("invalid business hours %q: %v", spec, err)
+	}
+	hours.window = window
+	*h = hours
+	return nil
+}
+
+// inBusinessHours reports whether t is in the BusinessHours, in their time
+// zone or else the one of the schedule. Any time is without BusinessHours.
+func (a *pingAdapter) inBusinessHours(t time.Time) bool {
+	if a.BusinessHours.spec == "" {
+		return true
+	}
+	if loc := a.BusinessHours.loc; loc != nil {
+		t = t.In(loc)
+	} else if a.Timezone != "" {
+		if loc, err := time.LoadLocation(a.Timezone); err == nil {
+			t = t.In(loc)
+		}
+	}
+	return a.BusinessHours.window.contains(t)
+}
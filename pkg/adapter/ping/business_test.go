@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestBusinessHoursDecode(t *testing.T) {
+	var got businessHours
+	if err := got.Decode("Mon-Fri 09:00-17:30 America/Chicago"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.loc == nil || got.loc.String() != "America/Chicago" {
+		t.Errorf("Expected the America/Chicago time zone, got %v", got.loc)
+	}
+	if w := got.window; len(w.days) != 5 || w.days[time.Saturday] || w.start != 9*time.Hour || w.end != 17*time.Hour+30*time.Minute {
+		t.Errorf("Unexpected window %+v", w)
+	}
+
+	if err := got.Decode("09:00-17:00"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.loc != nil || len(got.window.days) != 0 {
+		t.Errorf("Expected every day in the time zone of the schedule, got %+v", got)
+	}
+
+	for _, value := range []string{"09:00", "Mon-Fri 09:00-17:00 Nowhere/City", "Someday 09:00-17:00", "Mon 09:00-17:00 UTC extra"} {
+		if err := got.Decode(value); err == nil {
+			t.Errorf("Expected an error decoding %q", value)
+		}
+	}
+}
+
+func TestBusinessHours(t *testing.T) {
+	// 2020-08-03 is a Monday, Chicago is then UTC-5 and New York UTC-4.
+	testCases := map[string]struct {
+		hours    string
+		timezone string
+		now      time.Time
+		want     int
+	}{
+		"inside": {
+			hours: "Mon-Fri 09:00-17:00 America/Chicago",
+			now:   time.Date(2020, 8, 3, 15, 0, 0, 0, time.UTC),
+			want:  1,
+		},
+		"before opening": {
+			hours: "Mon-Fri 09:00-17:00 America/Chicago",
+			now:   time.Date(2020, 8, 3, 13, 0, 0, 0, time.UTC),
+			want:  0,
+		},
+		"after closing": {
+			hours: "Mon-Fri 09:00-17:00 America/Chicago",
+			now:   time.Date(2020, 8, 3, 22, 0, 0, 0, time.UTC),
+			want:  0,
+		},
+		"weekend": {
+			hours: "Mon-Fri 09:00-17:00 America/Chicago",
+			now:   time.Date(2020, 8, 8, 15, 0, 0, 0, time.UTC),
+			want:  0,
+		},
+		"weekday in UTC, weekend in the time zone": {
+			hours: "Mon-Fri 09:00-23:00 Asia/Tokyo",
+			// Friday 16:00 UTC is Saturday 01:00 in Tokyo.
+			now:  time.Date(2020, 8, 7, 16, 0, 0, 0, time.UTC),
+			want: 0,
+		},
+		"time zone overriding the schedule one": {
+			hours:    "Mon-Fri 09:00-17:00 America/Chicago",
+			timezone: "America/New_York",
+			// 09:30 in New York, 08:30 in Chicago.
+			now:  time.Date(2020, 8, 3, 13, 30, 0, 0, time.UTC),
+			want: 0,
+		},
+		"time zone of the schedule": {
+			hours:    "Mon-Fri 09:00-17:00",
+			timezone: "America/New_York",
+			now:      time.Date(2020, 8, 3, 13, 30, 0, 0, time.UTC),
+			want:     1,
+		},
+		"unset": {
+			now:  time.Date(2020, 8, 8, 3, 0, 0, 0, time.UTC),
+			want: 1,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var hours businessHours
+			if err := hours.Decode(tc.hours); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Data:          "data",
+				BusinessHours: hours,
+				Timezone:      tc.timezone,
+				Client:        ce,
+				clock:         clock.NewFakeClock(tc.now),
+			}
+			var logs bytes.Buffer
+			if err := a.cronTick(withBufferLogger(context.Background(), &logs)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := len(ce.Sent()); got != tc.want {
+				t.Errorf("Expected %d events to be sent, got %d", tc.want, got)
+			}
+			if got, want := strings.Contains(logs.String(), "ping outside business hours, skipping tick"), tc.want == 0; got != want {
+				t.Errorf("Expected the skipped tick logged %t, got logs %s", want, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// replayExtension is the CloudEvent extension marking the events of the
+// ticks missed while the adapter was down.
+const replayExtension = "pingreplay"
+
+type replayKey struct{}
+
+// withReplay returns ctx replaying the tick scheduled at scheduled.
+func withReplay(ctx context.Context, scheduled time.Time) context.Context {
+	return context.WithValue(ctx, replayKey{}, scheduled)
+}
+
+// replayFrom returns the scheduled time of the tick replayed with ctx, if any.
+func replayFrom(ctx context.Context) (time.Time, bool) {
+	scheduled, ok := ctx.Value(replayKey{}).(time.Time)
+	return scheduled, ok
+}
+
+// missedTicks returns, in order, the last limit ticks of sched after from
+// and up to to, along with the number of ticks missed in total.
+func missedTicks(sched cron.Schedule, from, to time.Time, limit int) ([]time.Time, int) {
+	var ticks []time.Time
+	missed := 0
+	for t := sched.Next(from); !t.IsZero() && !t.After(to); t = sched.Next(t) {
+		missed++
+		if limit <= 0 {
+			continue
+		}
+		ticks = append(ticks, t)
+		if len(ticks) > limit {
+			ticks = ticks[1:]
+		}
+	}
+	return ticks, missed
+}
+
+// catchup replays the ticks of sched missed since the last one fired.
+func (a *pingAdapter) catchup(ctx context.Context, sched cron.Schedule) {
+	logger := logging.FromContext(ctx)
+
+	from, err := a.lastFired()
+	if err != nil {
+		logger.Errorw("ping failed to read the last fired time, not catching up", zap.Error(err))
+		return
+	}
+	if from.IsZero() {
+		return
+	}
+
+	// The cron evaluates the schedule in the Timezone, so does the replay.
+	ticks, missed := missedTicks(sched, a.inTimezone(from), a.inTimezone(a.now()), a.CatchupLimit)
+	if missed == 0 {
+		return
+	}
+	logger.Infow("ping catching up on missed ticks", zap.Int("missed", missed), zap.Int("replayed", len(ticks)))
+	for _, t := range ticks {
+		if ctx.Err() != nil {
+			return
+		}
+		_ = a.cronTick(withReplay(ctx, t))
+	}
+}
+
+// lastFired returns the time of the last tick, as saved in
+// CatchupStateFile, defaulting to CatchupFrom.
+func (a *pingAdapter) lastFired() (time.Time, error) {
+	if a.CatchupStateFile != "" {
+		b, err := ioutil.ReadFile(a.CatchupStateFile)
+		if err == nil {
+			t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(b)))
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid last fired time in %s: %v", a.CatchupStateFile, err)
+			}
+			return t, nil
+		}
+		if !os.IsNotExist(err) {
+			return time.Time{}, fmt.Errorf("failed to read %s: %v", a.CatchupStateFile, err)
+		}
+	}
+	return a.CatchupFrom, nil
+}
+
+// saveLastFired saves t as the time of the last tick in CatchupStateFile.
+func (a *pingAdapter) saveLastFired(ctx context.Context, t time.Time) {
+	if !a.Catchup || a.CatchupStateFile == "" {
+		return
+	}
+	if err := ioutil.WriteFile(a.CatchupStateFile, []byte(t.Format(time.RFC3339Nano)), 0644); err != nil {
+		logging.FromContext(ctx).Errorw("ping failed to save the last fired time", zap.Error(err))
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestMissedTicks(t *testing.T) {
+	from := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time {
+		return time.Date(2020, 6, 1, h, m, 0, 0, time.UTC)
+	}
+	testCases := map[string]struct {
+		schedule   string
+		to         time.Time
+		limit      int
+		want       []time.Time
+		wantMissed int
+	}{
+		"none missed": {
+			schedule: "0 * * * *",
+			to:       at(12, 59),
+			limit:    10,
+		},
+		"all replayed": {
+			schedule:   "*/15 * * * *",
+			to:         at(13, 0),
+			limit:      10,
+			want:       []time.Time{at(12, 15), at(12, 30), at(12, 45), at(13, 0)},
+			wantMissed: 4,
+		},
+		"most recent replayed": {
+			schedule:   "*/15 * * * *",
+			to:         at(13, 10),
+			limit:      2,
+			want:       []time.Time{at(12, 45), at(13, 0)},
+			wantMissed: 4,
+		},
+		"none replayed": {
+			schedule:   "*/15 * * * *",
+			to:         at(13, 0),
+			limit:      0,
+			wantMissed: 4,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			sched, err := cron.ParseStandard(tc.schedule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, missed := missedTicks(sched, from, tc.to, tc.limit)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected ticks (-want, +got) = %v", diff)
+			}
+			if missed != tc.wantMissed {
+				t.Errorf("Expected %d missed ticks, got %d", tc.wantMissed, missed)
+			}
+		})
+	}
+}
+
+func TestStartCatchup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "last-fired")
+	lastFired := time.Now().Add(-5 * time.Minute)
+	if err := ioutil.WriteFile(stateFile, []byte(lastFired.Format(time.RFC3339Nano)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Schedule:         "* * * * *",
+		Data:             "data",
+		Catchup:          true,
+		CatchupStateFile: stateFile,
+		CatchupLimit:     3,
+		Client:           ce,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return a.isReady(), nil
+	}); err != nil {
+		t.Fatal("adapter not started")
+	}
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	sent := ce.Sent()
+	if len(sent) != 3 {
+		t.Fatalf("Expected 3 replayed events, got %d", len(sent))
+	}
+	var previous time.Time
+	for _, event := range sent {
+		if replay, ok := event.Extensions()[replayExtension]; !ok || replay != true {
+			t.Errorf("Expected %s extension, got %v", replayExtension, event.Extensions())
+		}
+		if !event.Time().After(previous) {
+			t.Errorf("Expected replayed ticks in order, got %v after %v", event.Time(), previous)
+		}
+		previous = event.Time()
+	}
+
+	b, err := ioutil.ReadFile(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := time.Parse(time.RFC3339Nano, string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.Equal(previous) {
+		t.Errorf("Expected last fired time %v to be saved, got %v", previous, saved)
+	}
+}
+
+func TestCatchupTimezone(t *testing.T) {
+	sched, err := cron.ParseStandard("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 08:00 and 10:00 in New York, missing the 09:00 tick.
+	from := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := time.Date(2020, 6, 1, 14, 0, 0, 0, time.UTC)
+
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Schedule:     "0 9 * * *",
+		Timezone:     "America/New_York",
+		Data:         "data",
+		Catchup:      true,
+		CatchupFrom:  from,
+		CatchupLimit: 10,
+		Client:       ce,
+		clock:        clock.NewFakeClock(now),
+	}
+	a.catchup(context.Background(), sched)
+
+	sent := ce.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 replayed event, got %d", len(sent))
+	}
+	if want := time.Date(2020, 6, 1, 13, 0, 0, 0, time.UTC); !sent[0].Time().Equal(want) {
+		t.Errorf("Expected the tick at %v to be replayed, got %v", want, sent[0].Time())
+	}
+}
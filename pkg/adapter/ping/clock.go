@@ -0,0 +1,34 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// Clock tells the time to the adapter, and times its waits. It is the
+// subset of clock.Clock used by the adapter so that tests can drive the
+// time-based behavior with a clock.FakeClock.
+type Clock interface {
+	Now() time.Time
+	Since(time.Time) time.Duration
+	NewTimer(time.Duration) clock.Timer
+}
+
+var _ Clock = clock.RealClock{}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestFakeClockTicks(t *testing.T) {
+	sched, err := cron.ParseStandard("* * * * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+	start := time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start)
+	ce := adaptertest.NewTestClient()
+	stopped := false
+
+	a := &pingAdapter{
+		Data:      "data",
+		JitterMax: 30 * time.Second,
+		EndTime:   start.Add(3 * time.Minute),
+		Client:    ce,
+		clock:     fakeClock,
+		schedule:  sched,
+		stop:      func() { stopped = true },
+	}
+
+	for i := 1; i <= 3; i++ {
+		done := make(chan error)
+		go func() {
+			done <- a.cronTick(context.Background())
+		}()
+
+		// The tick waits for its jitter on the fake clock.
+		if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+			return fakeClock.HasWaiters(), nil
+		}); err != nil {
+			t.Fatalf("tick %d did not wait for its jitter", i)
+		}
+		if got := len(ce.Sent()); got != i-1 {
+			t.Fatalf("Expected %d events to be sent before the jitter, got %d", i-1, got)
+		}
+		fakeClock.Step(a.JitterMax)
+		if err := <-done; err != nil {
+			t.Fatalf("tick %d: unexpected error: %v", i, err)
+		}
+		if got := len(ce.Sent()); got != i {
+			t.Fatalf("Expected %d events to be sent, got %d", i, got)
+		}
+		fakeClock.SetTime(start.Add(time.Duration(i) * time.Minute))
+	}
+
+	// The clock reached the end time.
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !stopped {
+		t.Error("Expected the adapter to stop at the end time")
+	}
+	if got := len(ce.Sent()); got != 3 {
+		t.Errorf("Expected no event to be sent at the end time, got %d", got-3)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func TestCompressionDecode(t *testing.T) {
+	testCases := map[string]struct {
+		value string
+		want  compression
+		error bool
+	}{
+		"none": {
+			value: "none",
+			want:  compressionNone,
+		},
+		"gzip": {
+			value: "gzip",
+			want:  compressionGzip,
+		},
+		"invalid": {
+			value: "brotli",
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got compression
+			err := got.Decode(tc.value)
+			if tc.error != (err != nil) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected compression %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCompression(t *testing.T) {
+	testCases := map[string]struct {
+		compression     compression
+		contentEncoding string
+	}{
+		"none": {
+			compression: compressionNone,
+		},
+		"gzip": {
+			compression:     compressionGzip,
+			contentEncoding: "gzip",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var contentEncoding string
+			var received *cloudevents.Event
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentEncoding = r.Header.Get("Content-Encoding")
+				if contentEncoding == "gzip" {
+					zr, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Errorf("failed to decompress body: %v", err)
+					}
+					r.Body = zr
+				}
+				event, err := binding.ToEvent(r.Context(), cehttp.NewMessageFromHttpRequest(r))
+				if err != nil {
+					t.Errorf("failed to decode event: %v", err)
+				}
+				received = event
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer sink.Close()
+
+			env := &envConfig{Compression: tc.compression}
+			ce := newTransportClient(t, sink.URL, env.WrapRoundTripper(http.DefaultTransport))
+
+			a := &pingAdapter{
+				Data:   "data",
+				Client: ce,
+			}
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if contentEncoding != tc.contentEncoding {
+				t.Errorf("Expected content encoding %q, got %q", tc.contentEncoding, contentEncoding)
+			}
+			if received == nil {
+				t.Fatal("no event received")
+			}
+			if got, want := string(received.Data()), `{"body":"data"}`; got != want {
+				t.Errorf("Expected data %q, got %q", want, got)
+			}
+		})
+	}
+}
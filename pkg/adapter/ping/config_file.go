@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// configFileEnv is the environment variable containing the path of the
+// configuration file.
+const configFileEnv = "CONFIG_FILE"
+
+var (
+	configFileMu sync.Mutex
+	// configFileVars are the environment variables set by loadConfigFile.
+	configFileVars = map[string]bool{}
+)
+
+// loadConfigFile sets the environment variables of the JSON or YAML
+// object in the file at path, keyed by environment variable name, which
+// are not set already, or were set by a previous load. String values are
+// used as is, other values as their JSON text. The variables of a
+// previous load no longer in the file are unset.
+func loadConfigFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return fmt.Errorf("invalid config file %s: must be a JSON or YAML object: %v", path, err)
+	}
+
+	known := envNames(reflect.TypeOf(envConfig{}))
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("invalid config file %s: unknown environment variables %s", path, strings.Join(unknown, ", "))
+	}
+
+	env := make(map[string]string, len(values))
+	for name, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			b, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("invalid value of %s in config file %s: %v", name, path, err)
+			}
+			s = string(b)
+		}
+		env[name] = s
+	}
+
+	configFileMu.Lock()
+	defer configFileMu.Unlock()
+	for name := range configFileVars {
+		if _, ok := env[name]; !ok {
+			os.Unsetenv(name)
+			delete(configFileVars, name)
+		}
+	}
+	for name, value := range env {
+		if _, ok := os.LookupEnv(name); ok && !configFileVars[name] {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return err
+		}
+		configFileVars[name] = true
+	}
+	return nil
+}
+
+// envNames returns the environment variable names of the fields of the
+// struct t, and of its embedded structs.
+func envNames(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for name := range envNames(f.Type) {
+				names[name] = true
+			}
+			continue
+		}
+		if name := f.Tag.Get("envconfig"); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
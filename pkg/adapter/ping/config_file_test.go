@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kelseyhightower/envconfig"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// unsetenv unsets names at the end of the test.
+func unsetenv(t *testing.T, names ...string) {
+	t.Cleanup(func() {
+		for _, name := range names {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `
+K_SINK: http://sink.example.com
+SCHEDULE: "*/5 * * * *"
+DATA: from the file
+RETRY_COUNT: 3
+SEND_TIMEOUT: 10s
+SCHEDULES:
+- schedule: "@hourly"
+  data: hourly
+`)
+	unsetenv(t, "K_SINK", "SCHEDULE", "DATA", "RETRY_COUNT", "SEND_TIMEOUT", "SCHEDULES")
+	os.Setenv("DATA", "from the environment")
+
+	if err := loadConfigFile(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	env := &envConfig{}
+	if err := envconfig.Process("", env); err != nil {
+		t.Fatalf("failed to process the environment: %v", err)
+	}
+
+	if env.Sink != "http://sink.example.com" || env.Schedule != "*/5 * * * *" {
+		t.Errorf("Expected the sink and schedule of the file, got %q and %q", env.Sink, env.Schedule)
+	}
+	if env.Data != "from the environment" {
+		t.Errorf("Expected the environment to override the file, got data %q", env.Data)
+	}
+	if env.RetryCount != 3 || env.SendTimeout != 10*time.Second {
+		t.Errorf("Expected the retry count 3 and send timeout 10s, got %d and %v", env.RetryCount, env.SendTimeout)
+	}
+	if diff := cmp.Diff(scheduleEntries{{Schedule: "@hourly", Data: "hourly"}}, env.Schedules); diff != "" {
+		t.Errorf("Unexpected schedules (-want, +got): %s", diff)
+	}
+}
+
+func TestLoadConfigFileInvalid(t *testing.T) {
+	testCases := map[string]struct {
+		content string
+		want    string
+	}{
+		"malformed": {
+			content: "SCHEDULE: [unterminated",
+			want:    "must be a JSON or YAML object",
+		},
+		"not an object": {
+			content: `["SCHEDULE"]`,
+			want:    "must be a JSON or YAML object",
+		},
+		"unknown variable": {
+			content: `{"SCHEDULE": "@hourly", "SCHEDUEL": "@daily"}`,
+			want:    "unknown environment variables SCHEDUEL",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			unsetenv(t, "SCHEDULE")
+			err := loadConfigFile(writeConfigFile(t, tc.content))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected an error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	if err := loadConfigFile(filepath.Join(os.TempDir(), "missing-config.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestNewEnvConfigInvalidConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `UNKNOWN: value`)
+	os.Setenv(configFileEnv, path)
+	defer os.Unsetenv(configFileEnv)
+
+	env := NewEnvConfig().(*envConfig)
+	err := env.Validate(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "UNKNOWN") {
+		t.Errorf("Expected Validate to report the config file error, got %v", err)
+	}
+}
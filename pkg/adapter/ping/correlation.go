@@ -0,0 +1,34 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// correlationIDExtension is the CloudEvent extension carrying the id of the
+// tick an event was sent by, the same in all the log lines of the tick.
+const correlationIDExtension = "correlationid"
+
+// withCorrelationID returns ctx of the tick of the correlation id, whose
+// logger logs it in every line.
+func withCorrelationID(ctx context.Context, id string) context.Context {
+	return logging.WithLogger(ctx, logging.FromContext(ctx).With(zap.String(correlationIDExtension, id)))
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestCorrelationID(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:      "data",
+		LogEvents: true,
+		Client:    ce,
+	}
+
+	var logs bytes.Buffer
+	ctx := withBufferLogger(context.Background(), &logs)
+	for i := 0; i < 2; i++ {
+		if err := a.cronTick(ctx); err != nil {
+			t.Fatalf("cronTick() = %v", err)
+		}
+	}
+
+	// The correlation id logged with the id of each sent event.
+	logged := map[string]string{}
+	scanner := bufio.NewScanner(&logs)
+	for scanner.Scan() {
+		var entry struct {
+			Msg           string `json:"msg"`
+			ID            string `json:"id"`
+			CorrelationID string `json:"correlationid"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("failed to decode log %q: %v", scanner.Text(), err)
+		}
+		if entry.Msg == "ping sent cloudevent" {
+			logged[entry.ID] = entry.CorrelationID
+		}
+	}
+
+	sent := ce.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(sent))
+	}
+	ids := map[string]bool{}
+	for _, event := range sent {
+		id, ok := event.Extensions()[correlationIDExtension].(string)
+		if !ok || id == "" {
+			t.Fatalf("Expected a correlationid extension, got %v", event.Extensions())
+		}
+		if got := logged[event.ID()]; got != id {
+			t.Errorf("Expected the correlation id %q logged for event %s, got %q", id, event.ID(), got)
+		}
+		ids[id] = true
+	}
+	if len(ids) != 2 {
+		t.Errorf("Expected a correlation id per tick, got %v", ids)
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// recordDrift reports the drift of a tick, the delay it fired with after
+// its scheduled time, and warns when it exceeds the DriftWarnThreshold.
+func (a *pingAdapter) recordDrift(ctx context.Context, drift time.Duration) {
+	if drift < 0 {
+		drift = 0
+	}
+	if err := a.reportDrift(drift); err != nil {
+		logging.FromContext(ctx).Warnw("ping failed to report the schedule drift", zap.Error(err))
+	}
+	if a.DriftWarnThreshold > 0 && drift > a.DriftWarnThreshold {
+		logging.FromContext(ctx).Warnw("ping tick fired late",
+			zap.Duration("drift", drift), zap.Duration("threshold", a.DriftWarnThreshold))
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestScheduleDrift(t *testing.T) {
+	wantTags := map[string]string{
+		metricskey.LabelNamespaceName: "testns",
+		metricskey.LabelName:          "testname",
+	}
+	slot := time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		delay    time.Duration
+		wantWarn bool
+	}{
+		"on time": {
+			delay: 200 * time.Millisecond,
+		},
+		"late": {
+			delay:    3 * time.Second,
+			wantWarn: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			resetMetrics()
+
+			a := &pingAdapter{
+				Data:               "data",
+				Name:               "testname",
+				Namespace:          "testns",
+				DriftWarnThreshold: time.Second,
+				Client:             adaptertest.NewTestClient(),
+				// The tick fires delay after its slot.
+				clock: clock.NewFakeClock(slot.Add(tc.delay)),
+			}
+
+			var logs bytes.Buffer
+			if err := a.cronTick(withSlot(withBufferLogger(context.Background(), &logs), slot)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			metricstest.CheckLastValueData(t, "schedule_drift_seconds", wantTags, tc.delay.Seconds())
+			if got := strings.Contains(logs.String(), "ping tick fired late"); got != tc.wantWarn {
+				t.Errorf("Expected the drift warning %t, got logs %s", tc.wantWarn, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// fileScheme is the URI scheme of the sinks events are appended to as
+// JSON lines.
+const fileScheme = "file://"
+
+// fileClient appends the events sent to a file:// target, its default one
+// or the target of the context, to the file as JSON lines. The events sent
+// to other targets are sent with next.
+type fileClient struct {
+	path string
+	next cloudevents.Client
+
+	// mu serializes the appends of concurrent ticks.
+	mu sync.Mutex
+}
+
+var _ cloudevents.Client = (*fileClient)(nil)
+
+// newFileClient returns a client appending the events to the file of
+// sink, a file:// URI.
+func newFileClient(sink string, next cloudevents.Client) (*fileClient, error) {
+	path, err := filePath(sink)
+	if err != nil {
+		return nil, err
+	}
+	return &fileClient{path: path, next: next}, nil
+}
+
+// filePath returns the path of the file:// URI sink.
+func filePath(sink string) (string, error) {
+	u, err := url.Parse(sink)
+	if err != nil || u.Path == "" {
+		return "", fmt.Errorf("invalid file sink %q: must be a file:// URI with a path", sink)
+	}
+	return u.Path, nil
+}
+
+func (c *fileClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	path := c.path
+	if target := cecontext.TargetFrom(ctx); target != nil {
+		if !strings.HasPrefix(target.String(), fileScheme) {
+			return c.next.Send(ctx, event)
+		}
+		path = target.Path
+	}
+
+	if event.Time().IsZero() {
+		event.SetTime(time.Now())
+	}
+	if err := event.Validate(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file sink %s: %v", path, err)
+	}
+	_, err = f.Write(append(b, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write to file sink %s: %v", path, err)
+	}
+	return nil
+}
+
+// Request appends event to the file. There is never a reply.
+func (c *fileClient) Request(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+	if target := cecontext.TargetFrom(ctx); target != nil && !strings.HasPrefix(target.String(), fileScheme) {
+		return c.next.Request(ctx, event)
+	}
+	return nil, c.Send(ctx, event)
+}
+
+func (c *fileClient) StartReceiver(context.Context, interface{}) error {
+	return errors.New("file sinks cannot receive events")
+}
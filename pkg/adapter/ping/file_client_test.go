@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ping")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.jsonl")
+
+	next := adaptertest.NewTestClient()
+	env := &envConfig{
+		EnvConfig: adapter.EnvConfig{Sink: "file://" + path},
+		Data:      `{"hello":"world"}`,
+	}
+	a := NewAdapter(context.Background(), env, next).(*pingAdapter)
+
+	const ticks = 10
+	var wg sync.WaitGroup
+	for i := 0; i < ticks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Errorf("cronTick() = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open the file sink: %v", err)
+	}
+	defer f.Close()
+
+	ids := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event cloudevents.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Unexpected line %q: %v", scanner.Text(), err)
+		}
+		if got := string(event.Data()); got != `{"hello":"world"}` {
+			t.Errorf("Expected the data %q, got %q", `{"hello":"world"}`, got)
+		}
+		ids[event.ID()] = true
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read the file sink: %v", err)
+	}
+	if len(ids) != ticks {
+		t.Errorf("Expected %d distinct events in the file sink, got %d", ticks, len(ids))
+	}
+	if got := len(next.Sent()); got != 0 {
+		t.Errorf("Expected no event to be sent over HTTP, got %d", got)
+	}
+}
+
+func TestFileSinkOtherTargets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ping")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.jsonl")
+
+	next := adaptertest.NewTestClient()
+	c, err := newFileClient("file://"+path, next)
+	if err != nil {
+		t.Fatalf("newFileClient() = %v", err)
+	}
+	a := &pingAdapter{
+		Data:   "data",
+		Sinks:  []string{"http://sink.example.com", "file://" + path},
+		Client: c,
+	}
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("cronTick() = %v", err)
+	}
+
+	if got := len(next.Sent()); got != 1 {
+		t.Errorf("Expected 1 event to be sent over HTTP, got %d", got)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the file sink: %v", err)
+	}
+	if len(b) == 0 || b[len(b)-1] != '\n' {
+		t.Errorf("Expected a JSON line in the file sink, got %q", b)
+	}
+}
+
+func TestEnvConfigValidateFileSink(t *testing.T) {
+	env := &envConfig{
+		EnvConfig: adapter.EnvConfig{Sink: "file://"},
+		Schedule:  "* * * * *",
+		Data:      "data",
+	}
+	if err := env.Validate(context.Background()); err == nil {
+		t.Error("Expected an error for a file sink without a path")
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// fireResult is the body returned by the /fire endpoint.
+type fireResult struct {
+	// ID is the id of the sent event, the first one of a batch.
+	ID string `json:"id,omitempty"`
+	// Error is the error of the tick.
+	Error string `json:"error,omitempty"`
+}
+
+// sentIDs records the ids of the events sent by a tick, and why it was
+// skipped.
+type sentIDs struct {
+	mu      sync.Mutex
+	ids     []string
+	skipped string
+}
+
+type sentIDsKey struct{}
+
+// withSentIDs returns ctx recording the ids of the events sent with it in
+// ids.
+func withSentIDs(ctx context.Context, ids *sentIDs) context.Context {
+	return context.WithValue(ctx, sentIDsKey{}, ids)
+}
+
+// recordSentID records id in the ids of ctx, if any.
+func recordSentID(ctx context.Context, id string) {
+	if ids, ok := ctx.Value(sentIDsKey{}).(*sentIDs); ok {
+		ids.mu.Lock()
+		ids.ids = append(ids.ids, id)
+		ids.mu.Unlock()
+	}
+}
+
+// recordSkipped records the reason the tick of ctx was skipped in the ids
+// of ctx, if any.
+func recordSkipped(ctx context.Context, reason string) {
+	if ids, ok := ctx.Value(sentIDsKey{}).(*sentIDs); ok {
+		ids.mu.Lock()
+		ids.skipped = reason
+		ids.mu.Unlock()
+	}
+}
+
+// fireHandler serves POST /fire, sending the event of an immediate tick.
+// The tick is refused while another one is running when SkipIfRunning is
+// set. A skipped tick is answered with a conflict, or too many requests
+// when rate limited. The tick runs in the context of the adapter, only
+// cancelled with the request.
+func (a *pingAdapter) fireHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if a.SkipIfRunning {
+		if !atomic.CompareAndSwapInt32(&a.running, 0, 1) {
+			writeFireResult(w, http.StatusConflict, fireResult{Error: "a tick is already running"})
+			return
+		}
+		defer atomic.StoreInt32(&a.running, 0)
+	}
+
+	ctx, cancel := context.WithCancel(a.runContext())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.Context().Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ids := &sentIDs{}
+	err := a.cronTick(withKind(withSentIDs(ctx, ids), kindManual))
+
+	var res fireResult
+	if len(ids.ids) > 0 {
+		res.ID = ids.ids[0]
+	}
+	switch {
+	case errors.Is(err, errRateLimited):
+		res.Error = err.Error()
+		writeFireResult(w, http.StatusTooManyRequests, res)
+	case errors.Is(err, errCircuitOpen):
+		res.Error = err.Error()
+		writeFireResult(w, http.StatusConflict, res)
+	case err != nil:
+		res.Error = err.Error()
+		writeFireResult(w, http.StatusBadGateway, res)
+	case res.ID == "":
+		res.Error = "tick skipped"
+		if ids.skipped != "" {
+			res.Error = "tick skipped: " + ids.skipped
+		}
+		writeFireResult(w, http.StatusConflict, res)
+	default:
+		writeFireResult(w, http.StatusOK, res)
+	}
+}
+
+func writeFireResult(w http.ResponseWriter, code int, res fireResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/pkg/logging"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestFire(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:            "data",
+		SkipIfRunning:   true,
+		AllowManualFire: true,
+		Client:          ce,
+	}
+	h := a.probeHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fire", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected /fire to return %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var res fireResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	sent := ce.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 event to be sent, got %d", len(sent))
+	}
+	if res.ID == "" || res.ID != sent[0].ID() {
+		t.Errorf("Expected the id %q of the sent event, got %q", sent[0].ID(), res.ID)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fire", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected GET /fire to return %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+
+	// A scheduled tick is running.
+	a.running = 1
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fire", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected /fire to return %d while a tick is running, got %d", http.StatusConflict, rec.Code)
+	}
+	if got := len(ce.Sent()); got != 1 {
+		t.Errorf("Expected no event to be sent while a tick is running, got %d", got-1)
+	}
+}
+
+func TestFireError(t *testing.T) {
+	sink := httptest.NewServer(http.HandlerFunc(sinkRejected))
+	defer sink.Close()
+
+	a := &pingAdapter{
+		Data:            "data",
+		AllowManualFire: true,
+		Client:          newSinkClient(t, sink.URL),
+	}
+	rec := httptest.NewRecorder()
+	a.probeHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fire", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("Expected /fire to return %d, got %d", http.StatusBadGateway, rec.Code)
+	}
+	var res fireResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || res.Error == "" {
+		t.Errorf("Expected the send error, got %q", rec.Body.String())
+	}
+}
+
+func TestFireSkipped(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fire")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pauseFile := filepath.Join(dir, "pause")
+	if err := ioutil.WriteFile(pauseFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		adapter *pingAdapter
+		code    int
+		want    string
+	}{
+		"paused": {
+			adapter: &pingAdapter{PauseFile: pauseFile},
+			code:    http.StatusConflict,
+			want:    "tick skipped: paused",
+		},
+		"max events": {
+			adapter: &pingAdapter{MaxEvents: 1, sent: 1, reserved: 1},
+			code:    http.StatusConflict,
+			want:    "tick skipped: max events reached",
+		},
+		"oversized": {
+			adapter: &pingAdapter{DataContentType: "text/plain", MaxPayloadBytes: 2, MaxPayloadAction: payloadActionSkip},
+			code:    http.StatusConflict,
+			want:    "tick skipped: payload of 4 bytes exceeds MAX_PAYLOAD_BYTES 2",
+		},
+		"circuit open": {
+			adapter: &pingAdapter{CircuitBreakerThreshold: 1, CircuitBreakerCooldown: time.Hour, breaker: circuitBreaker{state: breakerOpen, openedAt: time.Now()}},
+			code:    http.StatusConflict,
+			want:    errCircuitOpen.Error(),
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+			a := tc.adapter
+			a.Data, a.AllowManualFire, a.Client = "data", true, ce
+			var logs bytes.Buffer
+			a.logger = logging.FromContext(withBufferLogger(context.Background(), &logs))
+
+			rec := httptest.NewRecorder()
+			a.probeHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fire", nil))
+			if rec.Code != tc.code {
+				t.Errorf("Expected /fire to return %d, got %d: %s", tc.code, rec.Code, rec.Body.String())
+			}
+			var res fireResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+			if res.ID != "" || res.Error != tc.want {
+				t.Errorf("Expected the error %q, got %+v", tc.want, res)
+			}
+			if got := len(ce.Sent()); got != 0 {
+				t.Errorf("Expected no event to be sent, got %d", got)
+			}
+			if n == "paused" && !strings.Contains(logs.String(), "ping paused") {
+				t.Errorf("Expected the tick to log with the adapter logger, got %q", logs.String())
+			}
+		})
+	}
+}
+
+func TestFireMaxEvents(t *testing.T) {
+	ce := adaptertest.NewTestClientWithDelay(50 * time.Millisecond)
+	a := &pingAdapter{
+		Data:            "data",
+		MaxEvents:       3,
+		AllowManualFire: true,
+		Client:          ce,
+	}
+	h := a.probeHandler()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/fire", nil))
+		}()
+	}
+	wg.Wait()
+	if got := len(ce.Sent()); got != 3 {
+		t.Errorf("Expected the concurrent fires to send the 3 max events, got %d", got)
+	}
+}
+
+func TestFireDisabled(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:   "data",
+		Client: ce,
+	}
+	rec := httptest.NewRecorder()
+	a.probeHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fire", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected /fire to return %d by default, got %d", http.StatusNotFound, rec.Code)
+	}
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected no event to be sent, got %d", got)
+	}
+}
+
+func TestStartDrainFire(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	ce := adaptertest.NewTestClientWithDelay(500 * time.Millisecond)
+	a := &pingAdapter{
+		Schedule:        "0 0 1 1 *",
+		Data:            "data",
+		AllowManualFire: true,
+		ProbePort:       port,
+		DrainTimeout:    5 * time.Second,
+		Client:          ce,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+
+	fired := make(chan int)
+	go func() {
+		var code int
+		_ = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d/fire", port), "", nil)
+			if err != nil {
+				return false, nil
+			}
+			resp.Body.Close()
+			code = resp.StatusCode
+			return true, nil
+		})
+		fired <- code
+	}()
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&a.inflightCount) > 0, nil
+	}); err != nil {
+		t.Fatal("no fired tick in flight")
+	}
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got := len(ce.Sent()); got != 1 {
+		t.Errorf("Expected the fired event to be sent at shutdown, got %d events", got)
+	}
+	if code := <-fired; code != http.StatusOK {
+		t.Errorf("Expected /fire to return %d, got %d", http.StatusOK, code)
+	}
+}
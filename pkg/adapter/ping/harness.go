@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/robfig/cron/v3"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+)
+
+// TestAdapter is a ping adapter whose ticks are driven by the caller, at
+// the time of its Clock, for the tests of the code building on the ping
+// adapter. With a clock.FakeClock and the adapter test client, the tests
+// need neither real time nor network:
+//
+//	fakeClock.SetTime(a.Next())
+//	err := a.Tick()
+type TestAdapter struct {
+	a     *pingAdapter
+	ctx   context.Context
+	sched cron.Schedule
+}
+
+// NewTestAdapter returns a TestAdapter for the processed environment, as
+// returned by NewEnvConfig and processed by envconfig, that sends with
+// ceClient and tells the time with clk. Unlike NewAdapter, the sink is not
+// used: the events are only sent with ceClient. The adapter is stopped
+// once ctx is done.
+func NewTestAdapter(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client, clk Clock) (*TestAdapter, error) {
+	env := processed.(*envConfig)
+
+	a := newPingAdapter(env, ceClient)
+	a.clock = clk
+
+	payload, err := env.payloadProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.Payload = payload
+
+	sched, _, err := a.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	a.mu.Lock()
+	a.schedule = sched
+	a.stop = cancel
+	a.mu.Unlock()
+
+	return &TestAdapter{a: a, ctx: ctx, sched: sched}, nil
+}
+
+// Next returns the time of the next scheduled tick after the time of the
+// clock, in TIMEZONE, or the zero time for the random interval mode.
+func (t *TestAdapter) Next() time.Time {
+	if t.sched == nil {
+		return time.Time{}
+	}
+	return t.sched.Next(t.a.inTimezone(t.a.now()))
+}
+
+// Tick runs a scheduled tick at the time of the clock, and returns once
+// its events are sent.
+func (t *TestAdapter) Tick() error {
+	return t.a.cronTick(withSlot(t.ctx, t.a.now()))
+}
+
+// Done is closed once the adapter is stopped, by its context, END_TIME or
+// MAX_EVENTS.
+func (t *TestAdapter) Done() <-chan struct{} {
+	return t.ctx.Done()
+}
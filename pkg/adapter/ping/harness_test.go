@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"knative.dev/eventing/pkg/adapter/ping"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+// setenv sets the variables, and returns a func unsetting them.
+func setenv(vars map[string]string) func() {
+	for name, value := range vars {
+		os.Setenv(name, value)
+	}
+	return func() {
+		for name := range vars {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func ExampleNewTestAdapter() {
+	defer setenv(map[string]string{
+		"SCHEDULE": "*/5 * * * *",
+		"DATA":     "hello",
+	})()
+
+	env := ping.NewEnvConfig()
+	if err := envconfig.Process("", env); err != nil {
+		panic(err)
+	}
+
+	fakeClock := clock.NewFakeClock(time.Date(2020, 8, 10, 9, 31, 0, 0, time.UTC))
+	ce := adaptertest.NewTestClient()
+	a, err := ping.NewTestAdapter(context.Background(), env, ce, fakeClock)
+	if err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		fakeClock.SetTime(a.Next())
+		if err := a.Tick(); err != nil {
+			panic(err)
+		}
+	}
+
+	for _, event := range ce.Sent() {
+		fmt.Println(event.Time().UTC().Format(time.RFC3339), string(event.Data()))
+	}
+	// Output:
+	// 2020-08-10T09:35:00Z {"body":"hello"}
+	// 2020-08-10T09:40:00Z {"body":"hello"}
+}
+
+func ExampleTestAdapter_Next() {
+	defer setenv(map[string]string{
+		"SCHEDULE": "0 9 * * *",
+		"TIMEZONE": "America/New_York",
+		"DATA":     "hello",
+	})()
+
+	env := ping.NewEnvConfig()
+	if err := envconfig.Process("", env); err != nil {
+		panic(err)
+	}
+
+	fakeClock := clock.NewFakeClock(time.Date(2020, 8, 10, 12, 0, 0, 0, time.UTC))
+	a, err := ping.NewTestAdapter(context.Background(), env, adaptertest.NewTestClient(), fakeClock)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(a.Next().Format(time.RFC3339))
+	// Output:
+	// 2020-08-10T09:00:00-04:00
+}
+
+func ExampleTestAdapter_Done() {
+	defer setenv(map[string]string{
+		"SCHEDULE":   "@every 1m",
+		"DATA":       "hello",
+		"MAX_EVENTS": "2",
+	})()
+
+	env := ping.NewEnvConfig()
+	if err := envconfig.Process("", env); err != nil {
+		panic(err)
+	}
+
+	fakeClock := clock.NewFakeClock(time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC))
+	ce := adaptertest.NewTestClient()
+	a, err := ping.NewTestAdapter(context.Background(), env, ce, fakeClock)
+	if err != nil {
+		panic(err)
+	}
+
+	for ticks := 1; ; ticks++ {
+		fakeClock.SetTime(a.Next())
+		if err := a.Tick(); err != nil {
+			panic(err)
+		}
+		select {
+		case <-a.Done():
+			fmt.Printf("stopped after %d ticks and %d events\n", ticks, len(ce.Sent()))
+			return
+		default:
+		}
+	}
+	// Output:
+	// stopped after 2 ticks and 2 events
+}
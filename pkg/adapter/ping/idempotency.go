@@ -0,0 +1,57 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"net/http"
+)
+
+// idempotencyKeyHeader is the header of the idempotency key of the
+// requests, with IDEMPOTENCY_KEY.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKey struct{}
+
+// withIdempotencyKey returns ctx of the requests sending the event of id.
+func withIdempotencyKey(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, id)
+}
+
+// idempotencyKeyFrom returns the id of the event the request of ctx sends.
+func idempotencyKeyFrom(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(idempotencyKey{}).(string)
+	return id, ok && id != ""
+}
+
+// idempotencyTransport sets the id of the event a request sends as its
+// Idempotency-Key header, the same on every retry of the send, with
+// next. The requests carry their event in their context.
+type idempotencyTransport struct {
+	next http.RoundTripper
+}
+
+func (t *idempotencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id, ok := idempotencyKeyFrom(req.Context())
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+	// RoundTrippers must not modify the request.
+	req = req.Clone(req.Context())
+	req.Header.Set(idempotencyKeyHeader, id)
+	return t.next.RoundTrip(req)
+}
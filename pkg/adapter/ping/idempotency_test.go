@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	testCases := map[string]struct {
+		env       *envConfig
+		retryable retryableStatus
+	}{
+		"client retries": {
+			env: &envConfig{IdempotencyKey: true},
+		},
+		"adapter retries": {
+			env:       &envConfig{IdempotencyKey: true},
+			retryable: retryableStatus{"5xx"},
+		},
+		"disabled": {
+			env: &envConfig{},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var mu sync.Mutex
+			var ids, keys []string
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				ids = append(ids, r.Header.Get("Ce-Id"))
+				keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+				if len(ids) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer sink.Close()
+
+			a := &pingAdapter{
+				Data:            "data",
+				Encoding:        encodingBinary,
+				RetryCount:      2,
+				RetryBackoff:    time.Millisecond,
+				RetryableStatus: tc.retryable,
+				Client:          newTransportClient(t, sink.URL, tc.env.WrapRoundTripper(http.DefaultTransport)),
+			}
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(keys) != 2 {
+				t.Fatalf("Expected 2 requests, got %d", len(keys))
+			}
+			if ids[0] == "" || ids[1] != ids[0] {
+				t.Fatalf("Expected the same event id on both requests, got %q", ids)
+			}
+			for i, key := range keys {
+				want := ids[0]
+				if !tc.env.IdempotencyKey {
+					want = ""
+				}
+				if key != want {
+					t.Errorf("Expected Idempotency-Key %q on request %d, got %q", want, i, key)
+				}
+			}
+		})
+	}
+}
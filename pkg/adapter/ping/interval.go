@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// intervalMode is how the ticks are timed.
+type intervalMode string
+
+const (
+	// intervalModeCron ticks on the cron schedule.
+	intervalModeCron intervalMode = "cron"
+	// intervalModeRandom ticks after random intervals between
+	// MinInterval and MaxInterval.
+	intervalModeRandom intervalMode = "random"
+)
+
+// Decode implements envconfig.Decoder
+func (m *intervalMode) Decode(value string) error {
+	switch mode := intervalMode(value); mode {
+	case intervalModeCron, intervalModeRandom:
+		*m = mode
+		return nil
+	default:
+		return fmt.Errorf("invalid interval mode %q: must be %s or %s", value, intervalModeCron, intervalModeRandom)
+	}
+}
+
+// validateIntervals checks the bounds of the random intervals.
+func (a *pingAdapter) validateIntervals() error {
+	if a.MinInterval <= 0 {
+		return fmt.Errorf("invalid min interval %v: must be positive", a.MinInterval)
+	}
+	if a.MaxInterval < a.MinInterval {
+		return fmt.Errorf("invalid max interval %v: must not be less than the min interval %v", a.MaxInterval, a.MinInterval)
+	}
+	return nil
+}
+
+// randomInterval returns a random duration in [MinInterval, MaxInterval].
+func (a *pingAdapter) randomInterval() time.Duration {
+	n := int64(a.MaxInterval-a.MinInterval) + 1
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.rnd != nil {
+		return a.MinInterval + time.Duration(a.rnd.Int63n(n))
+	}
+	return a.MinInterval + time.Duration(rand.Int63n(n))
+}
+
+// runRandom ticks after random intervals until ctx is done. A tick starts
+// the interval to the next one once completed.
+func (a *pingAdapter) runRandom(ctx context.Context) {
+	for {
+		t := a.getClock().NewTimer(a.randomInterval())
+		select {
+		case <-t.C():
+			_ = a.cronTick(ctx)
+		case <-ctx.Done():
+			t.Stop()
+			return
+		}
+	}
+}
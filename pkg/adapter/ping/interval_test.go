@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestRandomInterval(t *testing.T) {
+	a := &pingAdapter{
+		MinInterval: time.Second,
+		MaxInterval: 3 * time.Second,
+		rnd:         rand.New(rand.NewSource(1)),
+	}
+	var min, max time.Duration
+	for i := 0; i < 1000; i++ {
+		d := a.randomInterval()
+		if d < a.MinInterval || d > a.MaxInterval {
+			t.Fatalf("Expected an interval in [%v, %v], got %v", a.MinInterval, a.MaxInterval, d)
+		}
+		if min == 0 || d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+	}
+	// The intervals spread over the bounds.
+	if min > 1100*time.Millisecond || max < 2900*time.Millisecond {
+		t.Errorf("Expected the intervals to spread over [%v, %v], got [%v, %v]", a.MinInterval, a.MaxInterval, min, max)
+	}
+}
+
+func TestStartRandomInterval(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC))
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:         "data",
+		IntervalMode: intervalModeRandom,
+		MinInterval:  time.Minute,
+		MaxInterval:  2 * time.Minute,
+		Client:       ce,
+		clock:        fakeClock,
+		rnd:          rand.New(rand.NewSource(1)),
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+
+	waitForTimer := func() {
+		t.Helper()
+		if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+			return fakeClock.HasWaiters(), nil
+		}); err != nil {
+			t.Fatal("start did not wait for an interval")
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		waitForTimer()
+
+		// No tick before the min interval.
+		fakeClock.Step(a.MinInterval - time.Nanosecond)
+		if !fakeClock.HasWaiters() {
+			t.Fatalf("Expected tick %d not to fire before the min interval", i)
+		}
+
+		// A tick by the max interval.
+		fakeClock.Step(a.MaxInterval - a.MinInterval + time.Nanosecond)
+		if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+			return len(ce.Sent()) == i, nil
+		}); err != nil {
+			t.Fatalf("Expected tick %d to fire by the max interval", i)
+		}
+	}
+
+	waitForTimer()
+	close(stop)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("start did not return after stop")
+	}
+	if got := len(ce.Sent()); got != 3 {
+		t.Errorf("Expected no tick after stop, got %d", got-3)
+	}
+}
+
+func TestStartBadIntervals(t *testing.T) {
+	testCases := map[string]*pingAdapter{
+		"zero min": {
+			MaxInterval: time.Second,
+		},
+		"max below min": {
+			MinInterval: 2 * time.Second,
+			MaxInterval: time.Second,
+		},
+		"catch-up": {
+			MinInterval: time.Second,
+			MaxInterval: 2 * time.Second,
+			Catchup:     true,
+		},
+	}
+	for n, a := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a.Data = "data"
+			a.IntervalMode = intervalModeRandom
+			stop := make(chan struct{})
+			close(stop)
+			if err := a.start(stop); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestIntervalModeDecode(t *testing.T) {
+	var m intervalMode
+	if err := m.Decode("random"); err != nil || m != intervalModeRandom {
+		t.Errorf("Decode(random) = %v, got %q", err, m)
+	}
+	if err := m.Decode("sometimes"); err == nil {
+		t.Error("Expected an error decoding interval mode sometimes")
+	}
+}
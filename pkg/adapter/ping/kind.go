@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+)
+
+// kindExtension is the CloudEvent extension telling how an event was
+// emitted, one of the pingKind values.
+const kindExtension = "pingkind"
+
+// pingKind is how an event was emitted.
+type pingKind string
+
+const (
+	// kindScheduled is the kind of the events of the ticks of the schedule.
+	kindScheduled pingKind = "scheduled"
+	// kindCatchup is the kind of the events of the replayed missed ticks.
+	kindCatchup pingKind = "catchup"
+	// kindManual is the kind of the events fired with /fire.
+	kindManual pingKind = "manual"
+	// kindLifecycle is the kind of the started and stopped events.
+	kindLifecycle pingKind = "lifecycle"
+)
+
+type kindKey struct{}
+
+// withKind returns ctx of a tick of kind.
+func withKind(ctx context.Context, kind pingKind) context.Context {
+	return context.WithValue(ctx, kindKey{}, kind)
+}
+
+// kindFrom returns the kind of the tick of ctx, scheduled by default.
+func kindFrom(ctx context.Context) pingKind {
+	if kind, ok := ctx.Value(kindKey{}).(pingKind); ok {
+		return kind
+	}
+	return kindScheduled
+}
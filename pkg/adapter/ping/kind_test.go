@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestPingKind(t *testing.T) {
+	testCases := map[string]struct {
+		emit func(a *pingAdapter)
+		want pingKind
+	}{
+		"scheduled": {
+			emit: func(a *pingAdapter) {
+				_ = a.cronTick(withSlot(context.Background(), time.Now()))
+			},
+			want: kindScheduled,
+		},
+		"catchup": {
+			emit: func(a *pingAdapter) {
+				_ = a.cronTick(withReplay(context.Background(), time.Now().Add(-time.Hour)))
+			},
+			want: kindCatchup,
+		},
+		"manual": {
+			emit: func(a *pingAdapter) {
+				a.probeHandler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/fire", nil))
+			},
+			want: kindManual,
+		},
+		"lifecycle": {
+			emit: func(a *pingAdapter) {
+				a.sendLifecycleEvent(context.Background(), startedEventType)
+			},
+			want: kindLifecycle,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Data:            "data",
+				AllowManualFire: true,
+				Client:          ce,
+			}
+			tc.emit(a)
+
+			if got := len(ce.Sent()); got != 1 {
+				t.Fatalf("Expected 1 event to be sent, got %d", got)
+			}
+			if got := ce.Sent()[0].Extensions()[kindExtension]; got != string(tc.want) {
+				t.Errorf("Expected %s %q, got %v", kindExtension, tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/types"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// processingTimeExtension is the extension of the reply of a sink carrying
+// the time it took to process the event, in milliseconds.
+const processingTimeExtension = "processingtime"
+
+// checkLatency warns when the processing time of the sink, from the
+// processingtime extension of its reply or else the round-trip time of
+// the request, exceeds the LatencyWarnThreshold.
+func (a *pingAdapter) checkLatency(ctx context.Context, reply *cloudevents.Event, roundTrip time.Duration) {
+	processing := roundTrip
+	if reply != nil {
+		if v, ok := reply.Extensions()[processingTimeExtension]; ok {
+			ms, err := types.ToInteger(v)
+			if err != nil {
+				logging.FromContext(ctx).Warnw("ping received an invalid processing time",
+					zap.Any(processingTimeExtension, v), zap.Error(err))
+			} else {
+				processing = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if processing > a.LatencyWarnThreshold {
+		logging.FromContext(ctx).Warnw("ping sink processed the cloudevent slowly",
+			zap.Duration("processingTime", processing),
+			zap.Duration("roundTrip", roundTrip),
+			zap.Duration("threshold", a.LatencyWarnThreshold))
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestLatencyWarnThreshold(t *testing.T) {
+	testCases := map[string]struct {
+		processingTime string
+		roundTrip      time.Duration
+		wantWarn       bool
+	}{
+		"above the threshold": {
+			processingTime: "1500",
+			wantWarn:       true,
+		},
+		"below the threshold": {
+			processingTime: "200",
+		},
+		"no reply": {
+			roundTrip: 200 * time.Millisecond,
+		},
+		"no reply slow round trip": {
+			roundTrip: 1500 * time.Millisecond,
+			wantWarn:  true,
+		},
+		"slow round trip below the threshold": {
+			processingTime: "200",
+			roundTrip:      1500 * time.Millisecond,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			fakeClock := clock.NewFakeClock(time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC))
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				fakeClock.Step(tc.roundTrip)
+				if tc.processingTime == "" {
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				w.Header().Set("Ce-Specversion", "1.0")
+				w.Header().Set("Ce-Id", "reply-1")
+				w.Header().Set("Ce-Type", "dev.knative.reply")
+				w.Header().Set("Ce-Source", "/sink")
+				w.Header().Set("Ce-Processingtime", tc.processingTime)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer sink.Close()
+
+			a := &pingAdapter{
+				Data:                 "data",
+				LatencyWarnThreshold: time.Second,
+				Client:               newSinkClient(t, sink.URL),
+				clock:                fakeClock,
+			}
+
+			var logs bytes.Buffer
+			if err := a.cronTick(withBufferLogger(context.Background(), &logs)); err != nil {
+				t.Fatalf("cronTick() = %v", err)
+			}
+			if got := strings.Contains(logs.String(), "ping sink processed the cloudevent slowly"); got != tc.wantWarn {
+				t.Errorf("Expected the latency warning %t, got logs %s", tc.wantWarn, logs.String())
+			}
+		})
+	}
+}
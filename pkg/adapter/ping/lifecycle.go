@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// startedEventType is the type of the event sent when start begins.
+	startedEventType = "dev.knative.source.ping.started"
+
+	// stoppedEventType is the type of the event sent on shutdown.
+	stoppedEventType = "dev.knative.source.ping.stopped"
+)
+
+// sendLifecycleEvent sends an event of eventType, without data, from the
+// source of the ticks, regardless of the RateLimit. A failure is only
+// logged.
+func (a *pingAdapter) sendLifecycleEvent(ctx context.Context, eventType string) {
+	event := cloudevents.NewEvent(a.specVersion())
+	event.SetID(a.newID())
+	event.SetType(eventType)
+	event.SetSource(a.source())
+	for name, value := range a.Extensions {
+		event.SetExtension(name, value)
+	}
+	event.SetExtension(kindExtension, string(kindLifecycle))
+
+	if err := a.send(withKind(a.withEncoding(ctx), kindLifecycle), event); err != nil {
+		logging.FromContext(ctx).Warnw("ping failed to send lifecycle event", zap.String("type", eventType), zap.Error(err))
+	}
+}
+
+// sendFinalEvent sends the event of the FinalEventType, once the in-flight
+// sends are drained, in what is left of the DrainTimeout since drainStart.
+// It is not sent once the drain window is over.
+func (a *pingAdapter) sendFinalEvent(ctx context.Context, drainStart time.Time) {
+	if a.FinalEventType == "" {
+		return
+	}
+
+	// ctx is already cancelled on shutdown.
+	finalCtx := logging.WithLogger(context.Background(), logging.FromContext(ctx))
+	if a.DrainTimeout > 0 {
+		left := a.DrainTimeout - a.getClock().Since(drainStart)
+		if left <= 0 {
+			logging.FromContext(ctx).Warnw("ping drain window over, not sending the final event", zap.String("type", a.FinalEventType))
+			return
+		}
+		var cancel context.CancelFunc
+		finalCtx, cancel = context.WithTimeout(finalCtx, left)
+		defer cancel()
+	}
+	a.sendLifecycleEvent(finalCtx, a.FinalEventType)
+}
+
+// sendStoppedEvent sends the stopped event in the background, as an
+// in-flight send waited for by drain.
+func (a *pingAdapter) sendStoppedEvent(ctx context.Context) {
+	// ctx is already cancelled on shutdown.
+	stopCtx := logging.WithLogger(context.Background(), logging.FromContext(ctx))
+	var cancel context.CancelFunc = func() {}
+	if a.DrainTimeout > 0 {
+		stopCtx, cancel = context.WithTimeout(stopCtx, a.DrainTimeout)
+	}
+
+	a.inflight.Add(1)
+	go func() {
+		defer a.inflight.Done()
+		defer cancel()
+		a.sendLifecycleEvent(stopCtx, stoppedEventType)
+	}()
+}
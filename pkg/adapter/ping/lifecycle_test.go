@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestLifecycleEventsRunOnce(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Schedule:        "* * * * *",
+		Data:            "data",
+		RunOnce:         true,
+		LifecycleEvents: true,
+		DrainTimeout:    5 * time.Second,
+		Client:          ce,
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := a.start(stop); err != nil {
+		t.Fatalf("start() = %v", err)
+	}
+
+	var got []string
+	for _, event := range ce.Sent() {
+		got = append(got, event.Type())
+	}
+	want := []string{startedEventType, a.eventType(), stoppedEventType}
+	if len(got) != len(want) {
+		t.Fatalf("Expected the events %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected the events %v, got %v", want, got)
+			break
+		}
+	}
+	for _, event := range ce.Sent() {
+		if event.Source() != a.source() {
+			t.Errorf("Expected the source %q, got %q", a.source(), event.Source())
+		}
+	}
+}
+
+func TestLifecycleEventsStop(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Schedule:        "0 0 1 1 *",
+		Data:            "data",
+		LifecycleEvents: true,
+		DrainTimeout:    5 * time.Second,
+		Client:          ce,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(ce.Sent()) > 0, nil
+	}); err != nil {
+		t.Fatal("Expected the started event to be sent")
+	}
+	if got := ce.Sent()[0].Type(); got != startedEventType {
+		t.Errorf("Expected the first event of type %s, got %s", startedEventType, got)
+	}
+
+	close(stop)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("start did not return after stop")
+	}
+
+	sent := ce.Sent()
+	if len(sent) != 2 || sent[1].Type() != stoppedEventType {
+		t.Errorf("Expected the started event followed by the stopped event, got %v", sent)
+	}
+}
+
+func TestFinalEvent(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Schedule:        "@every 1s",
+		Data:            "data",
+		LifecycleEvents: true,
+		FinalEventType:  "dev.knative.test.final",
+		DrainTimeout:    5 * time.Second,
+		Client:          ce,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+
+	// Wait for the started event and a tick.
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(ce.Sent()) > 1, nil
+	}); err != nil {
+		t.Fatal("Expected the started event and a tick to be sent")
+	}
+
+	close(stop)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("start did not return after stop")
+	}
+
+	sent := ce.Sent()
+	if got := sent[len(sent)-1]; got.Type() != "dev.knative.test.final" || got.Source() != a.source() {
+		t.Errorf("Expected the last event of type dev.knative.test.final from %q, got %v", a.source(), got)
+	}
+	var finals int
+	for _, event := range sent {
+		if event.Type() == "dev.knative.test.final" {
+			finals++
+		}
+	}
+	if finals != 1 {
+		t.Errorf("Expected a single final event, got %d", finals)
+	}
+}
+
+func TestStartBadFinalEventType(t *testing.T) {
+	a := &pingAdapter{
+		Schedule:       "* * * * *",
+		Data:           "data",
+		FinalEventType: "final type",
+		Client:         adaptertest.NewTestClient(),
+	}
+	if err := a.start(make(chan struct{})); err == nil {
+		t.Error("Expected an error for an invalid final event type")
+	}
+}
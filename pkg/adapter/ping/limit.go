@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// payloadAction is what is done with the payloads larger than
+// MaxPayloadBytes.
+type payloadAction string
+
+const (
+	// payloadActionFail fails at start with static data, and fails the
+	// ticks with dynamic data.
+	payloadActionFail payloadAction = "fail"
+	// payloadActionSkip skips the events.
+	payloadActionSkip payloadAction = "skip"
+	// payloadActionTruncate sends the first MaxPayloadBytes of the
+	// payloads.
+	payloadActionTruncate payloadAction = "truncate"
+)
+
+// Decode implements envconfig.Decoder
+func (p *payloadAction) Decode(value string) error {
+	switch action := payloadAction(value); action {
+	case payloadActionFail, payloadActionSkip, payloadActionTruncate:
+		*p = action
+		return nil
+	default:
+		return fmt.Errorf("invalid max payload action %q: must be %s, %s or %s", value, payloadActionFail, payloadActionSkip, payloadActionTruncate)
+	}
+}
+
+// staticPayload reports whether the data of every tick is the same, and
+// so checked against the MaxPayloadBytes once at start.
+func (a *pingAdapter) staticPayload() bool {
+	return !a.DataTemplate && !a.Batch && !a.DataReload && !a.DataStream &&
+		len(a.DataVariants) == 0 && a.Payload == nil && a.RandomDataSize == 0
+}
+
+// validatePayloadSize fails when the static data is larger than the
+// MaxPayloadBytes, with the fail action.
+func (a *pingAdapter) validatePayloadSize(ctx context.Context) error {
+	if a.MaxPayloadBytes <= 0 || a.MaxPayloadAction == payloadActionSkip || a.MaxPayloadAction == payloadActionTruncate ||
+		!a.staticPayload() {
+		return nil
+	}
+	data, contentType := a.data()
+	event := cloudevents.NewEvent()
+	if err := a.setEventData(ctx, &event, data, contentType, templateData{Time: a.now()}); err != nil {
+		return err
+	}
+	if size := len(event.Data()); size > a.MaxPayloadBytes {
+		return fmt.Errorf("invalid data: payload of %d bytes exceeds MAX_PAYLOAD_BYTES %d", size, a.MaxPayloadBytes)
+	}
+	return nil
+}
+
+// limitPayload applies the MaxPayloadAction to a payload of size bytes
+// larger than the MaxPayloadBytes. It returns false when the payload is
+// not sent, with the error failing the tick, if any.
+func (a *pingAdapter) limitPayload(ctx context.Context, size int64) (bool, error) {
+	if a.MaxPayloadBytes <= 0 || size <= int64(a.MaxPayloadBytes) {
+		return true, nil
+	}
+	err := fmt.Errorf("payload of %d bytes exceeds MAX_PAYLOAD_BYTES %d", size, a.MaxPayloadBytes)
+	switch a.MaxPayloadAction {
+	case payloadActionSkip:
+		logging.FromContext(ctx).Errorw("ping skipping an oversized payload", zap.Error(err))
+		recordSkipped(ctx, err.Error())
+		return false, nil
+	case payloadActionTruncate:
+		logging.FromContext(ctx).Warnw("ping truncating an oversized payload", zap.Error(err))
+		return true, nil
+	default:
+		logging.FromContext(ctx).Errorw("ping failed to send an oversized payload", zap.Error(err))
+		return false, err
+	}
+}
+
+// limitEventData applies the MaxPayloadAction to the data of event,
+// truncating it when needed. It returns false when event is not sent,
+// with the error failing the tick, if any. Truncated JSON is no longer
+// valid, it is sent as text/plain instead.
+func (a *pingAdapter) limitEventData(ctx context.Context, event *cloudevents.Event) (bool, error) {
+	ok, err := a.limitPayload(ctx, int64(len(event.Data())))
+	if max := a.truncatedSize(); ok && max > 0 && len(event.DataEncoded) > max {
+		event.DataEncoded = truncateData(event.DataEncoded, max)
+		if jsonContentType(event.DataContentType()) {
+			event.SetDataContentType(truncatedJSONContentType)
+		}
+	}
+	return ok, err
+}
+
+// truncatedJSONContentType is the content type of the truncated JSON
+// data.
+const truncatedJSONContentType = "text/plain; charset=utf-8"
+
+// truncateData returns the first max bytes of data, less when the last
+// rune of valid UTF-8 data would be split.
+func truncateData(data []byte, max int) []byte {
+	if utf8.Valid(data) {
+		for max > 0 && !utf8.RuneStart(data[max]) {
+			max--
+		}
+	}
+	return data[:max]
+}
+
+// jsonContentType reports whether contentType is application/json or a
+// +json media type.
+func jsonContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// limitFile applies the MaxPayloadAction to the streamed file at path.
+// It returns false when the file is not sent, with the error failing the
+// tick, if any. The truncation is applied by sendStream.
+func (a *pingAdapter) limitFile(ctx context.Context, path string) (bool, error) {
+	if a.MaxPayloadBytes <= 0 {
+		return true, nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read data file %s: %v", path, err)
+	}
+	return a.limitPayload(ctx, fi.Size())
+}
+
+// truncatedSize is the size the payloads are truncated to, zero when
+// they are not.
+func (a *pingAdapter) truncatedSize() int {
+	if a.MaxPayloadAction != payloadActionTruncate {
+		return 0
+	}
+	return a.MaxPayloadBytes
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestPayloadActionDecode(t *testing.T) {
+	var got payloadAction
+	for _, value := range []string{"fail", "skip", "truncate"} {
+		if err := got.Decode(value); err != nil || got != payloadAction(value) {
+			t.Errorf("Decode(%q) = %q, %v", value, got, err)
+		}
+	}
+	if err := got.Decode("compress"); err == nil {
+		t.Error("Expected an error decoding compress")
+	}
+}
+
+func TestMaxPayloadStatic(t *testing.T) {
+	testCases := map[string]struct {
+		action payloadAction
+		data   string
+		error  bool
+	}{
+		"fail": {
+			action: payloadActionFail,
+			data:   "0123456789",
+			error:  true,
+		},
+		"fail within the limit": {
+			action: payloadActionFail,
+			data:   "01234",
+		},
+		"skip": {
+			action: payloadActionSkip,
+			data:   "0123456789",
+		},
+		"truncate": {
+			action: payloadActionTruncate,
+			data:   "0123456789",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{
+				Schedule:         "* * * * *",
+				Data:             tc.data,
+				DataContentType:  "text/plain",
+				MaxPayloadBytes:  5,
+				MaxPayloadAction: tc.action,
+			}
+			if _, _, err := a.prepare(context.Background()); tc.error != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestMaxPayload(t *testing.T) {
+	testCases := map[string]struct {
+		action payloadAction
+		error  bool
+		want   string
+	}{
+		"fail": {
+			action: payloadActionFail,
+			error:  true,
+		},
+		"skip": {
+			action: payloadActionSkip,
+		},
+		"truncate": {
+			action: payloadActionTruncate,
+			want:   "ping-",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Name:             "ping-oversized",
+				Data:             "{{.Name}}",
+				DataTemplate:     true,
+				DataContentType:  "text/plain",
+				MaxPayloadBytes:  5,
+				MaxPayloadAction: tc.action,
+				Client:           ce,
+			}
+			if err := a.cronTick(context.Background()); tc.error != (err != nil) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.want == "" {
+				if got := len(ce.Sent()); got != 0 {
+					t.Errorf("Expected no event to be sent, got %d", got)
+				}
+				return
+			}
+			validateSent(t, ce, tc.want)
+		})
+	}
+}
+
+func TestMaxPayloadTruncateJSON(t *testing.T) {
+	testCases := map[string]struct {
+		data            string
+		contentType     string
+		max             int
+		want            string
+		wantContentType string
+	}{
+		"json": {
+			data:            `{"msg":"héllo"}`,
+			contentType:     "application/json",
+			max:             10,
+			want:            `{"msg":"h`,
+			wantContentType: "text/plain; charset=utf-8",
+		},
+		"json suffix": {
+			data:            `{"msg":"hello"}`,
+			contentType:     "application/cloudevents+json; charset=utf-8",
+			max:             4,
+			want:            `{"ms`,
+			wantContentType: "text/plain; charset=utf-8",
+		},
+		"message": {
+			data:            "héllo",
+			max:             10,
+			want:            `{"body":"h`,
+			wantContentType: "text/plain; charset=utf-8",
+		},
+		"text": {
+			data:            "héllo",
+			contentType:     "text/plain",
+			max:             2,
+			want:            "h",
+			wantContentType: "text/plain",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Data:             tc.data,
+				DataContentType:  tc.contentType,
+				MaxPayloadBytes:  tc.max,
+				MaxPayloadAction: payloadActionTruncate,
+				Client:           ce,
+			}
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			validateSent(t, ce, tc.want)
+			if got := ce.Sent()[0].DataContentType(); got != tc.wantContentType {
+				t.Errorf("Expected content type %q, got %q", tc.wantContentType, got)
+			}
+		})
+	}
+}
+
+func TestMaxPayloadBatch(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:             `["small","oversized","tiny"]`,
+		Batch:            true,
+		DataContentType:  "text/plain",
+		MaxPayloadBytes:  5,
+		MaxPayloadAction: payloadActionSkip,
+		Client:           ce,
+	}
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, event := range ce.Sent() {
+		got = append(got, string(event.Data()))
+	}
+	if len(got) != 2 || got[0] != "small" || got[1] != "tiny" {
+		t.Errorf("Expected the oversized event to be skipped, got %q", got)
+	}
+}
+
+func TestMaxPayloadStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		DataFromFile:     path,
+		DataStream:       true,
+		MaxPayloadBytes:  4,
+		MaxPayloadAction: payloadActionTruncate,
+		Client:           ce,
+	}
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	validateSent(t, ce, "0123")
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestPauseFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pause")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pauseFile := filepath.Join(dir, "paused")
+
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:      "data",
+		PauseFile: pauseFile,
+		Client:    ce,
+	}
+
+	tick := func(want int) {
+		t.Helper()
+		if err := a.cronTick(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := len(ce.Sent()); got != want {
+			t.Fatalf("Expected %d events to be sent, got %d", want, got)
+		}
+	}
+
+	tick(1)
+
+	if err := ioutil.WriteFile(pauseFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tick(1)
+	tick(1)
+
+	if err := os.Remove(pauseFile); err != nil {
+		t.Fatal(err)
+	}
+	tick(2)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// PayloadProvider provides the data of the event of each tick.
+type PayloadProvider interface {
+	// Payload returns the data and its content type. An empty content
+	// type defaults to application/octet-stream, except for EnvPayload.
+	Payload(ctx context.Context) ([]byte, string, error)
+}
+
+// PayloadProviderFactory creates the PayloadProvider registered under a
+// PAYLOAD_PROVIDER name.
+type PayloadProviderFactory func(ctx context.Context) (PayloadProvider, error)
+
+const (
+	// payloadProviderEnv selects the EnvPayload of DATA, as without
+	// PAYLOAD_PROVIDER.
+	payloadProviderEnv = "env"
+	// payloadProviderFile selects the FilePayload of DATA_FROM_FILE.
+	payloadProviderFile = "file"
+)
+
+var (
+	payloadProvidersMu sync.Mutex
+	payloadProviders   = map[string]PayloadProviderFactory{}
+)
+
+// RegisterPayloadProvider makes a PayloadProvider available under name to
+// PAYLOAD_PROVIDER, for custom builds of the adapter. It panics when name
+// is already registered or is one of the built-in env and file.
+func RegisterPayloadProvider(name string, factory PayloadProviderFactory) {
+	payloadProvidersMu.Lock()
+	defer payloadProvidersMu.Unlock()
+	if _, ok := payloadProviders[name]; ok || name == payloadProviderEnv || name == payloadProviderFile {
+		panic(fmt.Sprintf("payload provider %q already registered", name))
+	}
+	payloadProviders[name] = factory
+}
+
+// registeredPayloadProvider returns the factory registered under name.
+func registeredPayloadProvider(name string) (PayloadProviderFactory, bool) {
+	payloadProvidersMu.Lock()
+	defer payloadProvidersMu.Unlock()
+	factory, ok := payloadProviders[name]
+	return factory, ok
+}
+
+// validatePayloadProvider checks that name is empty, built-in or
+// registered.
+func (e *envConfig) validatePayloadProvider() error {
+	switch e.PayloadProvider {
+	case "":
+		return nil
+	case payloadProviderEnv:
+		if e.data() == "" {
+			return fmt.Errorf("payload provider %s requires DATA", payloadProviderEnv)
+		}
+		return nil
+	case payloadProviderFile:
+		if e.DataFromFile == "" {
+			return fmt.Errorf("payload provider %s requires DATA_FROM_FILE", payloadProviderFile)
+		}
+		return nil
+	}
+	if _, ok := registeredPayloadProvider(e.PayloadProvider); !ok {
+		return fmt.Errorf("unknown payload provider %q", e.PayloadProvider)
+	}
+	return nil
+}
+
+// payloadProvider returns the PayloadProvider selected by PAYLOAD_PROVIDER,
+// nil when unset or env: the adapter sends its data with an EnvPayload.
+func (e *envConfig) payloadProvider(ctx context.Context) (PayloadProvider, error) {
+	switch e.PayloadProvider {
+	case "", payloadProviderEnv:
+		return nil, nil
+	case payloadProviderFile:
+		return FilePayload{Path: e.DataFromFile, ContentType: e.DataContentType}, nil
+	}
+	factory, ok := registeredPayloadProvider(e.PayloadProvider)
+	if !ok {
+		return nil, fmt.Errorf("unknown payload provider %q", e.PayloadProvider)
+	}
+	return factory(ctx)
+}
+
+// EnvPayload provides the same Data on every tick. Its Data is sent like
+// DATA: templated, batched and wrapped in a Message as configured.
+type EnvPayload struct {
+	Data string
+	// ContentType overrides the DATA_CONTENT_TYPE when set.
+	ContentType string
+}
+
+// Payload implements PayloadProvider
+func (p EnvPayload) Payload(context.Context) ([]byte, string, error) {
+	return []byte(p.Data), p.ContentType, nil
+}
+
+// FilePayload provides the content of the file at Path, read on every
+// tick.
+type FilePayload struct {
+	Path string
+	// ContentType defaults to application/json when the content is valid
+	// JSON, text/plain otherwise.
+	ContentType string
+}
+
+// Payload implements PayloadProvider
+func (p FilePayload) Payload(context.Context) ([]byte, string, error) {
+	b, err := ioutil.ReadFile(p.Path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read data file %s: %v", p.Path, err)
+	}
+	return b, payloadContentType(b, p.ContentType), nil
+}
+
+// payloadContentType returns contentType, or the content type guessed
+// from b when empty.
+func payloadContentType(b []byte, contentType string) string {
+	switch {
+	case contentType != "":
+		return contentType
+	case json.Valid(b):
+		return "application/json"
+	default:
+		return "text/plain"
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+type fakePayload struct {
+	data        []byte
+	contentType string
+	err         error
+}
+
+func (p *fakePayload) Payload(context.Context) ([]byte, string, error) {
+	return p.data, p.contentType, p.err
+}
+
+func TestPayloadProvider(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:    "ignored",
+		Payload: &fakePayload{data: []byte("<ping/>"), contentType: "application/xml"},
+		Client:  ce,
+	}
+
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	event := ce.Sent()[0]
+	if got := string(event.Data()); got != "<ping/>" {
+		t.Errorf("Expected the provided data, got %q", got)
+	}
+	if got := event.DataContentType(); got != "application/xml" {
+		t.Errorf("Expected the provided content type, got %q", got)
+	}
+}
+
+func TestPayloadProviderError(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	want := errors.New("no payload")
+	a := &pingAdapter{
+		Payload: &fakePayload{err: want},
+		Client:  ce,
+	}
+
+	if err := a.cronTick(context.Background()); !errors.Is(err, want) {
+		t.Errorf("Expected the provider error, got %v", err)
+	}
+	if got := len(ce.Sent()); got != 0 {
+		t.Errorf("Expected no event sent, got %d", got)
+	}
+}
+
+func TestBuiltinPayloadProviders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(path, []byte(`{"from":"file"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		env             envConfig
+		wantData        string
+		wantContentType string
+		wantErr         bool
+	}{
+		"file": {
+			env:             envConfig{PayloadProvider: "file", DataFromFile: path},
+			wantData:        `{"from":"file"}`,
+			wantContentType: "application/json",
+		},
+		"missing file": {
+			env:     envConfig{PayloadProvider: "file", DataFromFile: filepath.Join(dir, "missing")},
+			wantErr: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p, err := tc.env.payloadProvider(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			data, contentType, err := p.Payload(context.Background())
+			if tc.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tc.wantData || contentType != tc.wantContentType {
+				t.Errorf("Expected %q (%s), got %q (%s)", tc.wantData, tc.wantContentType, data, contentType)
+			}
+		})
+	}
+}
+
+func TestEnvPayloadProvider(t *testing.T) {
+	testCases := map[string]envConfig{
+		"message":      {Data: "hello"},
+		"json":         {Data: `{"hello":"world"}`},
+		"content type": {Data: "a,b", DataContentType: "text/csv"},
+		"template":     {Data: `{"seq":{{.Sequence}}}`, DataTemplate: true},
+	}
+	for n, env := range testCases {
+		t.Run(n, func(t *testing.T) {
+			sent := func(env envConfig) string {
+				t.Helper()
+				env.Name, env.Namespace = "test-name", "test-ns"
+				ce := adaptertest.NewTestClient()
+				a := newPingAdapter(&env, ce)
+				a.clock = clock.NewFakeClock(time.Date(2020, 8, 10, 9, 0, 0, 0, time.UTC))
+				p, err := env.payloadProvider(context.Background())
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				a.Payload = p
+				if err := a.cronTick(context.Background()); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(ce.Sent()) != 1 {
+					t.Fatalf("Expected 1 event sent, got %d", len(ce.Sent()))
+				}
+				event := ce.Sent()[0]
+				event.SetID("id")
+				event.SetExtension(correlationIDExtension, "id")
+				return event.String()
+			}
+
+			want := sent(env)
+			env.PayloadProvider = "env"
+			if diff := cmp.Diff(want, sent(env)); diff != "" {
+				t.Errorf("Unexpected event with PAYLOAD_PROVIDER=env (-default, +env): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRegisterPayloadProvider(t *testing.T) {
+	fake := &fakePayload{data: []byte("custom")}
+	RegisterPayloadProvider("test-custom", func(context.Context) (PayloadProvider, error) {
+		return fake, nil
+	})
+
+	env := envConfig{PayloadProvider: "test-custom"}
+	if err := env.validatePayloadProvider(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := env.payloadProvider(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p != fake {
+		t.Errorf("Expected the registered provider, got %v", p)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a built-in name to panic")
+		}
+	}()
+	RegisterPayloadProvider("env", nil)
+}
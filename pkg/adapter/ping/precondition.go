@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// precondition is the last result of the probe of the PreconditionURL.
+type precondition struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// preconditionMet probes the PreconditionURL, unless probed less than
+// PreconditionCache ago. It returns why the precondition is not met, nil
+// when it is.
+func (a *pingAdapter) preconditionMet(ctx context.Context) error {
+	if a.PreconditionURL == "" {
+		return nil
+	}
+	p := &a.precondition
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := a.now()
+	if !p.checkedAt.IsZero() && now.Sub(p.checkedAt) < a.PreconditionCache {
+		return p.err
+	}
+	p.err = a.probePrecondition(ctx)
+	p.checkedAt = now
+	return p.err
+}
+
+// probePrecondition GETs the PreconditionURL, expecting a 2xx response
+// within the PreconditionTimeout. The probe is sent with a client of its
+// own, not to send the credentials and headers of the sink to the
+// PreconditionURL.
+func (a *pingAdapter) probePrecondition(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.PreconditionURL, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: a.PreconditionTimeout, Transport: http.DefaultTransport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("precondition %s returned %d", a.PreconditionURL, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestPrecondition(t *testing.T) {
+	testCases := map[string]struct {
+		status int
+		delay  time.Duration
+		want   int
+	}{
+		"healthy": {
+			status: http.StatusOK,
+			want:   1,
+		},
+		"unhealthy": {
+			status: http.StatusServiceUnavailable,
+			want:   0,
+		},
+		"timeout": {
+			status: http.StatusOK,
+			delay:  time.Second,
+			want:   0,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			dependency := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(tc.delay):
+				case <-r.Context().Done():
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer dependency.Close()
+
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Data:                "data",
+				PreconditionURL:     dependency.URL,
+				PreconditionTimeout: 100 * time.Millisecond,
+				Client:              ce,
+			}
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := len(ce.Sent()); got != tc.want {
+				t.Errorf("Expected %d events to be sent, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPreconditionCache(t *testing.T) {
+	var probes int32
+	healthy := int32(1)
+	dependency := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer dependency.Close()
+
+	fakeClock := clock.NewFakeClock(time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC))
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:              "data",
+		PreconditionURL:   dependency.URL,
+		PreconditionCache: 5 * time.Second,
+		Client:            ce,
+		clock:             fakeClock,
+	}
+	tick := func(wantSent int, wantProbes int32) {
+		t.Helper()
+		if err := a.cronTick(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := len(ce.Sent()); got != wantSent {
+			t.Errorf("Expected %d events to be sent, got %d", wantSent, got)
+		}
+		if got := atomic.LoadInt32(&probes); got != wantProbes {
+			t.Errorf("Expected %d probes, got %d", wantProbes, got)
+		}
+	}
+
+	tick(1, 1)
+	atomic.StoreInt32(&healthy, 0)
+	// The healthy result is reused.
+	fakeClock.Step(time.Second)
+	tick(2, 1)
+	fakeClock.Step(5 * time.Second)
+	tick(2, 2)
+}
+
+func TestPreconditionSinkHeaders(t *testing.T) {
+	var header http.Header
+	dependency := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer dependency.Close()
+
+	// The sink transport wrapping the shared client must not be used.
+	env := &envConfig{Headers: map[string][]string{"Authorization": {"Bearer secret"}, "X-Custom": {"value"}}}
+	defaultTransport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = env.WrapRoundTripper(http.DefaultTransport)
+	defer func() { http.DefaultClient.Transport = defaultTransport }()
+
+	a := &pingAdapter{PreconditionURL: dependency.URL, PreconditionTimeout: time.Second}
+	if err := a.probePrecondition(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"Authorization", "X-Custom"} {
+		if got := header.Get(name); got != "" {
+			t.Errorf("Expected no %s header on the probe, got %q", name, got)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultPreviewCount is the number of fire times returned by
+	// /schedule/preview without a count.
+	defaultPreviewCount = 10
+
+	// maxPreviewCount is the most fire times returned by
+	// /schedule/preview.
+	maxPreviewCount = 1000
+)
+
+// schedulePreview is the body returned by the /schedule/preview endpoint.
+type schedulePreview struct {
+	Schedule string      `json:"schedule"`
+	Now      time.Time   `json:"now"`
+	Next     []time.Time `json:"next"`
+}
+
+// previewHandler serves the next count fire times of the schedule, at
+// most maxPreviewCount.
+func (a *pingAdapter) previewHandler(w http.ResponseWriter, r *http.Request) {
+	count := defaultPreviewCount
+	if value := r.URL.Query().Get("count"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid count "+strconv.Quote(value)+": must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		count = n
+	}
+	if count > maxPreviewCount {
+		count = maxPreviewCount
+	}
+
+	preview, ok := a.schedulePreview(count)
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// schedulePreview computes the next count fire times of the parsed
+// schedule. It returns false until the schedule is parsed.
+func (a *pingAdapter) schedulePreview(count int) (schedulePreview, bool) {
+	a.mu.Lock()
+	spec, sched := a.Schedule, a.schedule
+	a.mu.Unlock()
+
+	if sched == nil {
+		return schedulePreview{}, false
+	}
+	now := a.inTimezone(a.now())
+	preview := schedulePreview{Schedule: spec, Now: now}
+	// Next returns the zero time for a schedule which never fires again.
+	for t := sched.Next(now); len(preview.Next) < count && !t.IsZero(); t = sched.Next(t) {
+		preview.Next = append(preview.Next, t)
+	}
+	return preview, true
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestSchedulePreview(t *testing.T) {
+	now := time.Date(2020, 8, 10, 9, 30, 20, 0, time.UTC)
+	sched, err := cron.ParseStandard("*/15 9-10 * * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+
+	a := &pingAdapter{
+		Schedule: "*/15 9-10 * * *",
+		clock:    clock.NewFakeClock(now),
+	}
+	h := a.probeHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schedule/preview", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected /schedule/preview to return %d before the schedule is parsed, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+	a.schedule = sched
+
+	testCases := map[string]struct {
+		query string
+		code  int
+		want  int
+	}{
+		"default count": {
+			code: http.StatusOK,
+			want: defaultPreviewCount,
+		},
+		"count": {
+			query: "?count=5",
+			code:  http.StatusOK,
+			want:  5,
+		},
+		"capped count": {
+			query: "?count=100000",
+			code:  http.StatusOK,
+			want:  maxPreviewCount,
+		},
+		"invalid count": {
+			query: "?count=abc",
+			code:  http.StatusBadRequest,
+		},
+		"negative count": {
+			query: "?count=-1",
+			code:  http.StatusBadRequest,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schedule/preview"+tc.query, nil))
+			if rec.Code != tc.code {
+				t.Fatalf("Expected /schedule/preview to return %d, got %d", tc.code, rec.Code)
+			}
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var got schedulePreview
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+			if got.Schedule != a.Schedule || !got.Now.Equal(now) {
+				t.Errorf("Unexpected schedule %q and now %v", got.Schedule, got.Now)
+			}
+			if len(got.Next) != tc.want {
+				t.Fatalf("Expected %d fire times, got %d", tc.want, len(got.Next))
+			}
+			prev := now
+			for i, next := range got.Next {
+				if !next.After(prev) {
+					t.Fatalf("Expected fire time %d %v after %v", i, next, prev)
+				}
+				if want := sched.Next(prev); !next.Equal(want) {
+					t.Fatalf("Expected fire time %d %v, got %v", i, want, next)
+				}
+				if next.Minute()%15 != 0 || next.Hour() < 9 || next.Hour() > 10 {
+					t.Fatalf("Fire time %d %v does not match the schedule", i, next)
+				}
+				prev = next
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const (
+	// livenessFactor is the number of schedule intervals without a tick
+	// after which the adapter is reported unhealthy.
+	livenessFactor = 3
+)
+
+// nextFire is the body returned by the /next endpoint.
+type nextFire struct {
+	Schedule string    `json:"schedule"`
+	Now      time.Time `json:"now"`
+	Next     time.Time `json:"next"`
+}
+
+// probeHandler serves the /healthz liveness and /readyz readiness probes,
+// the /next fire time of the schedule, the /schedule/preview of its next
+// fire times, the /status of the sends and, when allowed, /fire.
+func (a *pingAdapter) probeHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		if !a.isHealthy() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if !a.isReady() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/next", func(w http.ResponseWriter, _ *http.Request) {
+		next, ok := a.nextFire()
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(next)
+	})
+	mux.HandleFunc("/schedule/preview", a.previewHandler)
+	mux.HandleFunc("/status", a.statusHandler)
+	if a.AllowManualFire {
+		mux.HandleFunc("/fire", a.fireHandler)
+	}
+	return mux
+}
+
+// nextFire computes the next fire time of the parsed schedule. It returns
+// false until the schedule is parsed.
+func (a *pingAdapter) nextFire() (nextFire, bool) {
+	a.mu.Lock()
+	spec, sched := a.Schedule, a.schedule
+	a.mu.Unlock()
+
+	if sched == nil {
+		return nextFire{}, false
+	}
+	now := a.inTimezone(a.now())
+	return nextFire{Schedule: spec, Now: now, Next: sched.Next(now)}, true
+}
+
+func (a *pingAdapter) setReady(ready bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ready = ready
+	if ready {
+		a.lastTick = a.now()
+	}
+}
+
+func (a *pingAdapter) isReady() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.ready
+}
+
+// recordTick records the time of a tick.
+func (a *pingAdapter) recordTick() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastTick = a.now()
+}
+
+// isHealthy reports whether the cron ticked in the last livenessFactor
+// schedule intervals. The adapter is healthy until the cron is started.
+func (a *pingAdapter) isHealthy() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.ready || a.schedule == nil {
+		return true
+	}
+	last := a.inTimezone(a.lastTick)
+	interval := a.schedule.Next(last).Sub(last)
+	return a.now().Before(a.lastTick.Add(livenessFactor * interval))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestProbes(t *testing.T) {
+	sched, err := cron.ParseStandard("* * * * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+
+	testCases := map[string]struct {
+		ready       bool
+		sinceTick   time.Duration
+		wantHealthz int
+		wantReadyz  int
+	}{
+		"not started": {
+			wantHealthz: http.StatusOK,
+			wantReadyz:  http.StatusServiceUnavailable,
+		},
+		"healthy": {
+			ready:       true,
+			sinceTick:   90 * time.Second,
+			wantHealthz: http.StatusOK,
+			wantReadyz:  http.StatusOK,
+		},
+		"stalled": {
+			ready:       true,
+			sinceTick:   10 * time.Minute,
+			wantHealthz: http.StatusServiceUnavailable,
+			wantReadyz:  http.StatusOK,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			fakeClock := clock.NewFakeClock(time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC))
+
+			a := &pingAdapter{
+				Data:     "data",
+				Client:   adaptertest.NewTestClient(),
+				clock:    fakeClock,
+				schedule: sched,
+			}
+			if tc.ready {
+				a.setReady(true)
+				a.cronTick(context.Background())
+			}
+			fakeClock.Step(tc.sinceTick)
+
+			h := a.probeHandler()
+			for path, want := range map[string]int{"/healthz": tc.wantHealthz, "/readyz": tc.wantReadyz} {
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				if rec.Code != want {
+					t.Errorf("Expected %s to return %d, got %d", path, want, rec.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestNextEndpoint(t *testing.T) {
+	now := time.Date(2020, 8, 10, 9, 30, 20, 0, time.UTC)
+	sched, err := cron.ParseStandard("*/15 * * * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+
+	a := &pingAdapter{
+		Schedule: "*/15 * * * *",
+		clock:    clock.NewFakeClock(now),
+	}
+	h := a.probeHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/next", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected /next to return %d before the schedule is parsed, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+
+	a.schedule = sched
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/next", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected /next to return %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var got nextFire
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	if got.Schedule != a.Schedule {
+		t.Errorf("Expected schedule %q, got %q", a.Schedule, got.Schedule)
+	}
+	if !got.Now.Equal(now) {
+		t.Errorf("Expected now %v, got %v", now, got.Now)
+	}
+	if want := sched.Next(now); !got.Next.Equal(want) {
+		t.Errorf("Expected next %v, got %v", want, got.Next)
+	}
+}
+
+func TestHealthzTimezone(t *testing.T) {
+	// Monthly at midnight in Tokyo, which is 15:00 the day before in UTC.
+	sched, err := cron.ParseStandard("0 0 1 * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+	fakeClock := clock.NewFakeClock(time.Date(2020, 6, 30, 15, 0, 0, 0, time.UTC))
+	a := &pingAdapter{
+		Timezone: "Asia/Tokyo",
+		clock:    fakeClock,
+		schedule: sched,
+	}
+	a.setReady(true)
+	a.recordTick()
+	fakeClock.Step(48 * time.Hour)
+
+	if !a.isHealthy() {
+		t.Error("Expected the adapter to be healthy two days after a monthly tick")
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// queueFullPolicy is which tick is dropped when the send queue is full.
+type queueFullPolicy string
+
+const (
+	// queueFullDropOldest drops the oldest queued tick.
+	queueFullDropOldest queueFullPolicy = "drop-oldest"
+	// queueFullDropNewest drops the tick being queued.
+	queueFullDropNewest queueFullPolicy = "drop-newest"
+)
+
+// Decode implements envconfig.Decoder
+func (p *queueFullPolicy) Decode(value string) error {
+	switch policy := queueFullPolicy(value); policy {
+	case queueFullDropOldest, queueFullDropNewest:
+		*p = policy
+		return nil
+	default:
+		return fmt.Errorf("invalid queue full policy %q: must be %s or %s", value, queueFullDropOldest, queueFullDropNewest)
+	}
+}
+
+// sendQueue buffers the ticks of the cron for a single sender, so that a
+// slow sink does not delay the schedule.
+type sendQueue struct {
+	// mu serializes the ticks queued concurrently by the cron.
+	mu     sync.Mutex
+	policy queueFullPolicy
+	ticks  chan context.Context
+}
+
+// newSendQueue returns a queue of at most size ticks.
+func newSendQueue(size int, policy queueFullPolicy) *sendQueue {
+	return &sendQueue{
+		policy: policy,
+		ticks:  make(chan context.Context, size),
+	}
+}
+
+// push queues tick, dropping a tick per the policy when the queue is full.
+// It returns false when a tick was dropped.
+func (q *sendQueue) push(tick context.Context) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case q.ticks <- tick:
+		return true
+	default:
+	}
+	if q.policy == queueFullDropNewest {
+		return false
+	}
+
+	// Only runQueue receives besides push, the queue has room once the
+	// oldest tick is gone.
+	select {
+	case <-q.ticks:
+	default:
+	}
+	q.ticks <- tick
+	return false
+}
+
+// queued returns a job queuing the context of its tick, built by tick,
+// instead of sending it. The queued ticks are sent by runQueue.
+func (a *pingAdapter) queued(ctx context.Context, tick func() context.Context) cron.Job {
+	return cron.FuncJob(func() {
+		if a.queue.push(tick()) {
+			return
+		}
+		logging.FromContext(ctx).Warnw("ping send queue full, dropping a tick", zap.String("policy", string(a.queue.policy)))
+		if err := a.reportDropped(); err != nil {
+			logging.FromContext(ctx).Errorw("failed to record the dropped tick", zap.Error(err))
+		}
+	})
+}
+
+// runQueue sends the queued ticks one at a time until ctx is done. The
+// ticks still queued then are not sent. With SkipIfRunning, a queued tick
+// is skipped while a manual fire is running, and the reverse.
+func (a *pingAdapter) runQueue(ctx context.Context) {
+	q := a.queue
+	for {
+		select {
+		case tick := <-q.ticks:
+			var job cron.Job = cron.FuncJob(func() { _ = a.cronTick(tick) })
+			if a.SkipIfRunning {
+				job = a.skipIfRunning(ctx, job)
+			}
+			job.Run()
+		case <-ctx.Done():
+			if n := len(q.ticks); n > 0 {
+				logging.FromContext(ctx).Infow("ping stopping with queued ticks", zap.Int("dropped", n))
+			}
+			return
+		}
+	}
+}
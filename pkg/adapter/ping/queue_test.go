@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestQueueFullPolicy(t *testing.T) {
+	base := time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC)
+	at := func(i int) time.Time { return base.Add(time.Duration(i) * time.Minute) }
+
+	testCases := map[string]struct {
+		policy queueFullPolicy
+		want   []time.Time
+	}{
+		"drop oldest": {
+			policy: queueFullDropOldest,
+			want:   []time.Time{at(3), at(4)},
+		},
+		"drop newest": {
+			policy: queueFullDropNewest,
+			want:   []time.Time{at(1), at(2)},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			resetMetrics()
+
+			a := &pingAdapter{
+				Name:      "testname",
+				Namespace: "testns",
+				queue:     newSendQueue(2, tc.policy),
+			}
+
+			// Saturate the queue, no sender is running.
+			for i := 1; i <= 4; i++ {
+				slot := at(i)
+				a.queued(context.Background(), func() context.Context {
+					return withSlot(context.Background(), slot)
+				}).Run()
+			}
+
+			var got []time.Time
+			for len(a.queue.ticks) > 0 {
+				slot, _ := slotFrom(<-a.queue.ticks)
+				got = append(got, slot)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected queued ticks (-want, +got): %s", diff)
+			}
+			metricstest.CheckCountData(t, "events_dropped_total", map[string]string{
+				metricskey.LabelNamespaceName: "testns",
+				metricskey.LabelName:          "testname",
+			}, 2)
+		})
+	}
+}
+
+func TestRunQueue(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:   "data",
+		Client: ce,
+		queue:  newSendQueue(3, queueFullDropOldest),
+	}
+	for i := 0; i < 3; i++ {
+		a.queue.push(context.Background())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.runQueue(ctx)
+		close(done)
+	}()
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(ce.Sent()) == 3, nil
+	}); err != nil {
+		t.Fatalf("Expected the 3 queued ticks to be sent, got %d", len(ce.Sent()))
+	}
+	cancel()
+	<-done
+}
+
+func TestRunQueueSkipIfRunning(t *testing.T) {
+	ce := adaptertest.NewTestClientWithDelay(200 * time.Millisecond)
+	a := &pingAdapter{
+		Data:            "data",
+		SkipIfRunning:   true,
+		AllowManualFire: true,
+		Client:          ce,
+		queue:           newSendQueue(1, queueFullDropOldest),
+	}
+	a.queue.push(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.runQueue(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&a.running) == 1, nil
+	}); err != nil {
+		t.Fatal("Expected the queued tick to run through the SkipIfRunning guard")
+	}
+	rec := httptest.NewRecorder()
+	a.probeHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fire", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected /fire to return %d while a queued tick is running, got %d", http.StatusConflict, rec.Code)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(ce.Sent()) == 1, nil
+	}); err != nil {
+		t.Fatalf("Expected the queued tick to be sent, got %d", len(ce.Sent()))
+	}
+}
+
+func TestStartNegativeQueueSize(t *testing.T) {
+	a := &pingAdapter{
+		Schedule:  "* * * * *",
+		QueueSize: -1,
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
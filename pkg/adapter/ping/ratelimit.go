@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// errRateLimited is returned by the sends dropped by the rate limiter.
+var errRateLimited = errors.New("rate limit exceeded, dropping cloudevent")
+
+// tokenBucket is a rate limiter refilled with rate tokens per second, up
+// to burst tokens. Every send takes a token.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full bucket of rate tokens per second, with at
+// most burst tokens, at least one.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes a token at now, and returns how long to wait for it to
+// be available.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	if now.After(b.last) {
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a token reserved but not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// waitRate waits for the RateLimit to allow a send. The send is dropped,
+// and errRateLimited returned, when it would wait beyond the next tick of
+// the schedule.
+func (a *pingAdapter) waitRate(ctx context.Context) error {
+	if a.limiter == nil {
+		return nil
+	}
+	now := a.now()
+	delay := a.limiter.reserve(now)
+	if delay <= 0 {
+		return nil
+	}
+	if a.beyondNextTick(now, delay) {
+		a.limiter.cancel()
+		logging.FromContext(ctx).Warnw("ping rate limit exceeded, dropping cloudevent", zap.Duration("delay", delay))
+		if err := a.reportDropped(); err != nil {
+			logging.FromContext(ctx).Errorw("failed to record the dropped event", zap.Error(err))
+		}
+		return errRateLimited
+	}
+
+	t := a.getClock().NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return nil
+	case <-ctx.Done():
+		a.limiter.cancel()
+		return ctx.Err()
+	}
+}
+
+// beyondNextTick reports whether waiting delay from now ends after the
+// next tick of the schedule, if parsed.
+func (a *pingAdapter) beyondNextTick(now time.Time, delay time.Duration) bool {
+	a.mu.Lock()
+	sched := a.schedule
+	a.mu.Unlock()
+	if sched == nil {
+		return false
+	}
+	next := sched.Next(now)
+	return !next.IsZero() && now.Add(delay).After(next)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC)
+	b := newTokenBucket(1, 2)
+	for i, want := range []time.Duration{0, 0, time.Second, 2 * time.Second} {
+		if got := b.reserve(now); got != want {
+			t.Errorf("Expected reservation %d to wait %v, got %v", i, want, got)
+		}
+	}
+	b.cancel()
+	b.cancel()
+	// Refilled by 3 tokens, up to the burst.
+	if got := b.reserve(now.Add(10 * time.Second)); got != 0 {
+		t.Errorf("Expected a refilled bucket, got a wait of %v", got)
+	}
+}
+
+func TestRateLimitBurst(t *testing.T) {
+	resetMetrics()
+
+	sched, err := cron.ParseStandard("* * * * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+	// The next tick is half a second away.
+	now := time.Date(2020, 8, 10, 9, 30, 59, int(500*time.Millisecond), time.UTC)
+
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Name:      "testname",
+		Namespace: "testns",
+		Data:      `["a","b","c","d","e"]`,
+		Batch:     true,
+		Client:    ce,
+		clock:     clock.NewFakeClock(now),
+		schedule:  sched,
+		limiter:   newTokenBucket(1, 2),
+	}
+	if err := a.cronTick(context.Background()); err == nil {
+		t.Error("Expected the rate limited events to fail the tick")
+	}
+	if got := len(ce.Sent()); got != 2 {
+		t.Errorf("Expected the burst of 2 events to be sent, got %d", got)
+	}
+	metricstest.CheckCountData(t, "events_dropped_total", map[string]string{
+		metricskey.LabelNamespaceName: "testns",
+		metricskey.LabelName:          "testname",
+	}, 3)
+}
+
+func TestRateLimitWait(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:    `["a","b","c"]`,
+		Batch:   true,
+		Client:  ce,
+		limiter: newTokenBucket(50, 1),
+	}
+	start := time.Now()
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(ce.Sent()); got != 3 {
+		t.Errorf("Expected 3 events to be sent, got %d", got)
+	}
+	// Two events wait for a token, 20ms each.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected the sends to be spread over 40ms, took %v", elapsed)
+	}
+}
+
+func TestRateLimitLifecycle(t *testing.T) {
+	sched, err := cron.ParseStandard("* * * * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+	// The next tick is half a second away.
+	now := time.Date(2020, 8, 10, 9, 30, 59, int(500*time.Millisecond), time.UTC)
+
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:     "data",
+		Client:   ce,
+		clock:    clock.NewFakeClock(now),
+		schedule: sched,
+		limiter:  newTokenBucket(1, 1),
+	}
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The bucket is empty: a replayed tick is dropped, the lifecycle
+	// events are not.
+	if err := a.cronTick(withReplay(context.Background(), now.Add(-time.Minute))); err == nil {
+		t.Error("Expected the rate limited replayed tick to fail")
+	}
+	a.sendLifecycleEvent(context.Background(), stoppedEventType)
+	sent := ce.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 events to be sent, got %d", len(sent))
+	}
+	if got := sent[1].Type(); got != stoppedEventType {
+		t.Errorf("Expected the %s event to be sent, got %s", stoppedEventType, got)
+	}
+}
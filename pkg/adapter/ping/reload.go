@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// reload re-reads the schedule and data and swaps the cron entry id for one
+// running job on the new schedule. The returned entry id is the one now
+// running. When the new configuration is invalid, the old one is kept.
+//
+// The environment of the process does not change once started, so only
+// the ConfigFile, loaded again in the environment, can change the schedule
+// and data, and the DataFromFile the data.
+func (a *pingAdapter) reload(ctx context.Context, c *cron.Cron, id cron.EntryID, job cron.Job) cron.EntryID {
+	logger := logging.FromContext(ctx)
+
+	if a.ConfigFile != "" {
+		if err := loadConfigFile(a.ConfigFile); err != nil {
+			logger.Errorw("failed to reload ping config file, keeping the current configuration", zap.Error(err))
+			return id
+		}
+	}
+
+	env := &envConfig{}
+	if err := envconfig.Process("", env); err != nil {
+		logger.Errorw("failed to reload ping configuration, keeping the current one", zap.Error(err))
+		return id
+	}
+
+	sched, err := a.parseSchedule(env.Schedule)
+	if err != nil {
+		logger.Errorw("failed to reload ping schedule, keeping the current one", zap.Error(err))
+		return id
+	}
+
+	data := env.data()
+	if a.DataFromFile != "" {
+		b, err := ioutil.ReadFile(a.DataFromFile)
+		if err != nil {
+			logger.Errorw("failed to reload ping data, keeping the current one",
+				zap.Error(fmt.Errorf("failed to read data file %s: %v", a.DataFromFile, err)))
+			return id
+		}
+		data = string(b)
+	}
+	if err := a.validateData(ctx, data); err != nil {
+		logger.Errorw("failed to reload ping data, keeping the current one", zap.Error(err))
+		return id
+	}
+
+	a.mu.Lock()
+	a.Schedule = env.Schedule
+	a.schedule = sched
+	if a.DataFromFile != "" {
+		a.fileData = data
+	} else {
+		a.Data = data
+	}
+	a.mu.Unlock()
+
+	newID := c.Schedule(a.slots.track(sched), job)
+	c.Remove(id)
+	logger.Infow("ping configuration reloaded", zap.String("schedule", env.Schedule))
+	return newID
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestStartReload(t *testing.T) {
+	defer os.Unsetenv("SCHEDULE")
+	defer os.Unsetenv("DATA")
+
+	ce := adaptertest.NewTestClient()
+	reload := make(chan os.Signal)
+
+	a := &pingAdapter{
+		Schedule:      "0 0 1 1 *",
+		Data:          "old",
+		Client:        ce,
+		reloadSignals: reload,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+
+	os.Setenv("SCHEDULE", "@every 1s")
+	os.Setenv("DATA", "new")
+	reload <- syscall.SIGHUP
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(ce.Sent()) > 0, nil
+	}); err != nil {
+		t.Fatal("no event sent on the reloaded schedule")
+	}
+	validateSent(t, ce, `{"body":"new"}`)
+
+	// An invalid schedule keeps the current one. The second signal is only
+	// received once the first reload is done.
+	os.Setenv("SCHEDULE", "bogus")
+	reload <- syscall.SIGHUP
+	reload <- syscall.SIGHUP
+
+	a.mu.Lock()
+	schedule := a.Schedule
+	entries := a.cron.Entries()
+	a.mu.Unlock()
+	if schedule != "@every 1s" {
+		t.Errorf("Expected schedule %q to be kept, got %q", "@every 1s", schedule)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 cron entry, got %d", len(entries))
+	}
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStartReloadConfigFile(t *testing.T) {
+	defer func() {
+		configFileMu.Lock()
+		defer configFileMu.Unlock()
+		for name := range configFileVars {
+			os.Unsetenv(name)
+			delete(configFileVars, name)
+		}
+	}()
+
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("SCHEDULE: '0 0 1 1 *'\nDATA: old\n")
+	if err := loadConfigFile(path); err != nil {
+		t.Fatalf("loadConfigFile() = %v", err)
+	}
+
+	ce := adaptertest.NewTestClient()
+	reload := make(chan os.Signal)
+	a := &pingAdapter{
+		Schedule:      "0 0 1 1 *",
+		Data:          "old",
+		ConfigFile:    path,
+		Client:        ce,
+		reloadSignals: reload,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+
+	write("SCHEDULE: '@every 1s'\nDATA: new\n")
+	reload <- syscall.SIGHUP
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(ce.Sent()) > 0, nil
+	}); err != nil {
+		t.Fatal("no event sent on the schedule of the reloaded config file")
+	}
+	validateSent(t, ce, `{"body":"new"}`)
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// handleReply logs reply, the event the sink replied with, and forwards it
+// to the reply sink. A missing reply or a failure to forward it is only
+// logged.
+func (a *pingAdapter) handleReply(ctx context.Context, reply *cloudevents.Event) {
+	logger := logging.FromContext(ctx)
+	if reply == nil {
+		logger.Debug("ping received no reply")
+		return
+	}
+	logger.Infow("ping received reply",
+		zap.String("id", reply.ID()),
+		zap.String("type", reply.Type()),
+		zap.String("source", reply.Source()))
+
+	if a.ReplySink == "" {
+		return
+	}
+
+	// Like the dead letter send, the forward must not be affected by the
+	// timeout of the request.
+	replyCtx := trace.NewContext(context.Background(), trace.FromContext(ctx))
+	if a.SendTimeout > 0 {
+		var cancel context.CancelFunc
+		replyCtx, cancel = context.WithTimeout(replyCtx, a.SendTimeout)
+		defer cancel()
+	}
+	replyCtx = cloudevents.ContextWithTarget(a.withEncoding(replyCtx), a.ReplySink)
+
+	if result := a.Client.Send(replyCtx, *reply); !cloudevents.IsACK(result) {
+		logger.Errorw("ping failed to forward reply", zap.String("replySink", a.ReplySink), zap.Error(result))
+	}
+}
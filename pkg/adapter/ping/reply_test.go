@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func TestExpectReply(t *testing.T) {
+	testCases := map[string]struct {
+		reply     bool
+		wantReply bool
+	}{
+		"reply forwarded": {
+			reply:     true,
+			wantReply: true,
+		},
+		"no reply": {},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if !tc.reply {
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				w.Header().Set("Ce-Specversion", "1.0")
+				w.Header().Set("Ce-Id", "reply-1")
+				w.Header().Set("Ce-Type", "dev.knative.reply")
+				w.Header().Set("Ce-Source", "/sink")
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"reply":true}`))
+			}))
+			defer sink.Close()
+
+			received := make(chan cloudevents.Event, 1)
+			replySink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				event, err := binding.ToEvent(r.Context(), cehttp.NewMessageFromHttpRequest(r))
+				if err != nil {
+					t.Errorf("reply sink received an invalid event: %v", err)
+				} else {
+					received <- *event
+				}
+				sinkAccepted(w, r)
+			}))
+			defer replySink.Close()
+
+			a := &pingAdapter{
+				Data:        "data",
+				Sink:        sink.URL,
+				ExpectReply: true,
+				ReplySink:   replySink.URL,
+				Client:      newSinkClient(t, sink.URL),
+			}
+
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("cronTick() = %v", err)
+			}
+
+			select {
+			case event := <-received:
+				if !tc.wantReply {
+					t.Fatalf("Expected no reply to be forwarded, got %v", event)
+				}
+				if event.ID() != "reply-1" || event.Type() != "dev.knative.reply" {
+					t.Errorf("Expected the reply reply-1 of type dev.knative.reply, got %s of type %s", event.ID(), event.Type())
+				}
+				if got := string(event.Data()); got != `{"reply":true}` {
+					t.Errorf("Expected the reply data %q, got %q", `{"reply":true}`, got)
+				}
+			default:
+				if tc.wantReply {
+					t.Error("Expected the reply to be forwarded to the reply sink")
+				}
+			}
+		})
+	}
+}
+
+func TestStartBadReplySink(t *testing.T) {
+	testCases := map[string]*pingAdapter{
+		"without expect reply": {
+			Schedule:  "* * * * *",
+			ReplySink: "http://reply.example.com",
+		},
+		"relative": {
+			Schedule:    "* * * * *",
+			ExpectReply: true,
+			ReplySink:   "/reply",
+		},
+	}
+	for n, a := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a.Data = "data"
+			stop := make(chan struct{})
+			close(stop)
+			if err := a.start(stop); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// retryableStatus are the HTTP status codes, such as 503, and classes,
+// such as 5xx, of the failed sends which are retried, decoded from a
+// comma-separated list.
+type retryableStatus []string
+
+// Decode implements envconfig.Decoder
+func (s *retryableStatus) Decode(value string) error {
+	var status retryableStatus
+	for _, code := range strings.Split(value, ",") {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if !isStatusClass(code) {
+			if n, err := strconv.Atoi(code); err != nil || n < 100 || n > 599 {
+				return fmt.Errorf("invalid retryable status %q: must be a status code such as 503 or a class such as 5xx", code)
+			}
+		}
+		status = append(status, code)
+	}
+	*s = status
+	return nil
+}
+
+// isStatusClass reports whether code is a class of status codes such as 5xx.
+func isStatusClass(code string) bool {
+	return len(code) == 3 && code[0] >= '1' && code[0] <= '5' && code[1:] == "xx"
+}
+
+// matches reports whether the status code sc is one of s.
+func (s retryableStatus) matches(sc int) bool {
+	code := strconv.Itoa(sc)
+	for _, c := range s {
+		if c == code || (isStatusClass(c) && c[0] == code[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryable reports whether the send failed with result is retried: its
+// response status is one of s, or it got no response.
+func (s retryableStatus) retryable(result protocol.Result) bool {
+	var res *cehttp.Result
+	if cloudevents.ResultAs(result, &res) {
+		return s.matches(res.StatusCode)
+	}
+	var uErr *url.Error
+	return errors.As(result, &uErr)
+}
+
+// requestWithRetries sends event with the retries of ctx. With
+// RetryableStatus, the sends are retried by the adapter on those status
+// only, rather than by the client on its own set of status. Streamed
+// sends are always retried by the adapter.
+func (a *pingAdapter) requestWithRetries(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+	rp := cecontext.RetriesFrom(ctx)
+	status := a.RetryableStatus
+	if _, ok := streamFrom(ctx); ok && len(status) == 0 {
+		// A streamed request cannot be sent again by the client, its body
+		// is read once.
+		status = defaultRetryableStatus
+	}
+	if len(status) == 0 || rp.Strategy == cecontext.BackoffStrategyNone {
+		return a.request(ctx, event)
+	}
+
+	once := cecontext.WithRetryParams(ctx, &cecontext.RetryParams{Strategy: cecontext.BackoffStrategyNone})
+	for retry := 0; ; retry++ {
+		reply, result := a.request(once, event)
+		if cloudevents.IsACK(result) || !status.retryable(result) {
+			return reply, result
+		}
+		if err := rp.Backoff(ctx, retry+1); err != nil {
+			return reply, result
+		}
+	}
+}
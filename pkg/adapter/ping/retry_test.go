@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRetryableStatusDecode(t *testing.T) {
+	var got retryableStatus
+	if err := got.Decode("503, 5XX,429"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(retryableStatus{"503", "5xx", "429"}, got); diff != "" {
+		t.Errorf("Unexpected status (-want, +got): %s", diff)
+	}
+
+	for _, value := range []string{"abc", "99", "600", "6xx", "50x"} {
+		if err := got.Decode(value); err == nil {
+			t.Errorf("Expected an error decoding %q", value)
+		}
+	}
+}
+
+func TestRetryableStatus(t *testing.T) {
+	testCases := map[string]struct {
+		retryable retryableStatus
+		status    int
+		want      int32
+	}{
+		"400 not retried": {
+			retryable: retryableStatus{"5xx"},
+			status:    http.StatusBadRequest,
+			want:      1,
+		},
+		"503 retried": {
+			retryable: retryableStatus{"5xx"},
+			status:    http.StatusServiceUnavailable,
+			want:      3,
+		},
+		"listed code retried": {
+			retryable: retryableStatus{"500"},
+			status:    http.StatusInternalServerError,
+			want:      3,
+		},
+		"429 not listed": {
+			retryable: retryableStatus{"5xx"},
+			status:    http.StatusTooManyRequests,
+			want:      1,
+		},
+		"default 400 not retried": {
+			status: http.StatusBadRequest,
+			want:   1,
+		},
+		"default 503 retried": {
+			status: http.StatusServiceUnavailable,
+			want:   3,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var requests int32
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				w.WriteHeader(tc.status)
+			}))
+			defer s.Close()
+
+			a := &pingAdapter{
+				Data:            "data",
+				RetryCount:      2,
+				RetryBackoff:    time.Millisecond,
+				RetryableStatus: tc.retryable,
+				Client:          newSinkClient(t, s.URL),
+			}
+			if err := a.cronTick(context.Background()); err == nil {
+				t.Fatal("Expected the send to fail")
+			}
+			if got := atomic.LoadInt32(&requests); got != tc.want {
+				t.Errorf("Expected %d requests, got %d", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleEntry is an additional schedule of the adapter, sending its own
+// data to its own sink.
+type scheduleEntry struct {
+	Schedule string `json:"schedule"`
+	Data     string `json:"data"`
+	// Sink defaults to the adapter sink.
+	Sink string `json:"sink,omitempty"`
+}
+
+// scheduleEntries are the entries of SCHEDULES, decoded from a JSON array.
+type scheduleEntries []scheduleEntry
+
+// Decode implements envconfig.Decoder
+func (s *scheduleEntries) Decode(value string) error {
+	var entries scheduleEntries
+	if err := json.Unmarshal([]byte(value), &entries); err != nil {
+		return fmt.Errorf("invalid schedules: must be a JSON array of {schedule, data, sink} objects: %v", err)
+	}
+	*s = entries
+	return nil
+}
+
+type entryKey struct{}
+
+// withEntry returns ctx of a tick of entry.
+func withEntry(ctx context.Context, entry scheduleEntry) context.Context {
+	return context.WithValue(ctx, entryKey{}, entry)
+}
+
+// entryFrom returns the entry of the tick of ctx, if any.
+func entryFrom(ctx context.Context) (scheduleEntry, bool) {
+	entry, ok := ctx.Value(entryKey{}).(scheduleEntry)
+	return entry, ok
+}
+
+// prepareEntries parses the schedules of the Schedules and validates their
+// data. Two schedules firing together to the same sink are rejected, as
+// their events could not be told apart.
+func (a *pingAdapter) prepareEntries(ctx context.Context) ([]cron.Schedule, error) {
+	if len(a.Schedules) == 0 {
+		return nil, nil
+	}
+	if a.IntervalMode == intervalModeRandom {
+		return nil, errors.New("SCHEDULES requires the cron interval mode")
+	}
+
+	type key struct{ schedule, sink string }
+	seen := map[key]int{{strings.Join(strings.Fields(a.Schedule), " "), ""}: -1}
+	scheds := make([]cron.Schedule, 0, len(a.Schedules))
+	for i, entry := range a.Schedules {
+		sched, err := a.parseSchedule(entry.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedules entry %d: %w", i, err)
+		}
+		if entry.Data == "" {
+			return nil, fmt.Errorf("invalid schedules entry %d: data must be set", i)
+		}
+		if err := a.validateData(ctx, entry.Data); err != nil {
+			return nil, fmt.Errorf("invalid schedules entry %d: %v", i, err)
+		}
+		// An invalid sink would not be set as the target of the events,
+		// sent to the adapter sink instead.
+		if entry.Sink != "" {
+			if u, err := url.Parse(entry.Sink); err != nil {
+				return nil, fmt.Errorf("invalid schedules entry %d: invalid sink: %v", i, err)
+			} else if !u.IsAbs() {
+				return nil, fmt.Errorf("invalid schedules entry %d: sink %s must be an absolute URL", i, entry.Sink)
+			}
+		}
+
+		k := key{strings.Join(strings.Fields(entry.Schedule), " "), entry.Sink}
+		if k.sink == a.Sink {
+			k.sink = ""
+		}
+		if j, ok := seen[k]; ok {
+			other := "SCHEDULE"
+			if j >= 0 {
+				other = fmt.Sprintf("entry %d", j)
+			}
+			return nil, fmt.Errorf("invalid schedules entry %d: schedule %s sends to the same sink as %s", i, entry.Schedule, other)
+		}
+		seen[k] = i
+		scheds = append(scheds, sched)
+	}
+	return scheds, nil
+}
+
+// scheduleEntries registers a cron entry for each of the Schedules, with
+// their parsed schedules scheds. The ticks of the entries are neither
+// skipped while running nor queued.
+func (a *pingAdapter) scheduleEntries(ctx context.Context, c *cron.Cron, scheds []cron.Schedule) {
+	for i, entry := range a.Schedules {
+		entryCtx := withEntry(ctx, entry)
+		s := &slots{}
+		c.Schedule(s.track(scheds[i]), cron.FuncJob(func() {
+			_ = a.cronTick(withSlot(entryCtx, s.fired(a.now())))
+		}))
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestScheduleEntriesDecode(t *testing.T) {
+	var entries scheduleEntries
+	if err := entries.Decode(`[{"schedule":"@every 1m","data":"a"},{"schedule":"@hourly","data":"b","sink":"http://b"}]`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := scheduleEntries{
+		{Schedule: "@every 1m", Data: "a"},
+		{Schedule: "@hourly", Data: "b", Sink: "http://b"},
+	}
+	if diff := cmp.Diff(want, entries); diff != "" {
+		t.Errorf("Unexpected entries (-want, +got): %s", diff)
+	}
+
+	if err := entries.Decode(`{"schedule":"@hourly"}`); err == nil {
+		t.Error("Expected an error for a JSON object")
+	}
+}
+
+func TestScheduleEntries(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string][]string{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], string(b))
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	a := &pingAdapter{
+		// The adapter schedule does not fire during the test.
+		Schedule:        "0 0 1 1 *",
+		Data:            "main",
+		DataContentType: "text/plain",
+		Schedules: []scheduleEntry{
+			{Schedule: "@every 1s", Data: "first", Sink: s.URL + "/first"},
+			{Schedule: "@every 1s", Data: "second", Sink: s.URL + "/second"},
+		},
+		Client: newSinkClient(t, s.URL+"/main"),
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received["/first"]) > 0 && len(received["/second"]) > 0, nil
+	}); err != nil {
+		t.Fatal("Expected both entries to send an event")
+	}
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for path, data := range map[string]string{"/first": "first", "/second": "second"} {
+		for _, got := range received[path] {
+			if got != data {
+				t.Errorf("Expected %s to receive %q, got %q", path, data, got)
+			}
+		}
+	}
+	if got := received["/main"]; len(got) != 0 {
+		t.Errorf("Expected no event to the adapter sink, got %v", got)
+	}
+}
+
+func TestPrepareEntries(t *testing.T) {
+	testCases := map[string]struct {
+		entries []scheduleEntry
+		error   bool
+	}{
+		"independent": {
+			entries: []scheduleEntry{
+				{Schedule: "@every 1m", Data: "a"},
+				{Schedule: "@every 1m", Data: "b", Sink: "http://b.example.com"},
+			},
+		},
+		"same schedule and sink": {
+			entries: []scheduleEntry{
+				{Schedule: "@every 1m", Data: "a", Sink: "http://a.example.com"},
+				{Schedule: "@every  1m", Data: "b", Sink: "http://a.example.com"},
+			},
+			error: true,
+		},
+		"same as the adapter schedule": {
+			entries: []scheduleEntry{
+				{Schedule: "* * * * *", Data: "a"},
+			},
+			error: true,
+		},
+		"same as the adapter schedule and sink": {
+			entries: []scheduleEntry{
+				{Schedule: "* * * * *", Data: "a", Sink: "http://sink.example.com"},
+			},
+			error: true,
+		},
+		"no data": {
+			entries: []scheduleEntry{
+				{Schedule: "@every 1m"},
+			},
+			error: true,
+		},
+		"invalid sink": {
+			entries: []scheduleEntry{
+				{Schedule: "@every 1m", Data: "a", Sink: "http://[::1"},
+			},
+			error: true,
+		},
+		"relative sink": {
+			entries: []scheduleEntry{
+				{Schedule: "@every 1m", Data: "a", Sink: "a.example.com"},
+			},
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{
+				Schedule:  "* * * * *",
+				Data:      "data",
+				Sink:      "http://sink.example.com",
+				Schedules: tc.entries,
+			}
+			_, err := a.prepareEntries(context.Background())
+			if tc.error != (err != nil) {
+				t.Errorf("Expected error %v, got %v", tc.error, err)
+			}
+		})
+	}
+}
+
+func TestPrepareEntriesInvalidSchedule(t *testing.T) {
+	a := &pingAdapter{
+		Schedule: "* * * * *",
+		Schedules: []scheduleEntry{
+			{Schedule: "@every 1m", Data: "a"},
+			{Schedule: "bogus", Data: "b"},
+		},
+	}
+	_, err := a.prepareEntries(context.Background())
+	var invalid *ErrInvalidSchedule
+	if !errors.As(err, &invalid) || invalid.Schedule != "bogus" {
+		t.Errorf("Expected an ErrInvalidSchedule of entry 1, got %v", err)
+	}
+}
+
+func TestPrepareEntriesInvalidSink(t *testing.T) {
+	a := &pingAdapter{
+		Schedule: "* * * * *",
+		Schedules: []scheduleEntry{
+			{Schedule: "@every 1m", Data: "a", Sink: "http://a.example.com"},
+			{Schedule: "@every 1m", Data: "b", Sink: "b.example.com/ping"},
+		},
+	}
+	_, err := a.prepareEntries(context.Background())
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid schedules entry 1: ") {
+		t.Errorf("Expected an error of entry 1, got %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// jsonSchema is the subset of JSON Schema the data is validated against.
+// A schema with other keywords, except the annotations, is rejected.
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Enum                 []interface{}          `json:"enum"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaTypes is the type keyword, a single type or a list of types.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = many
+	return nil
+}
+
+// schemaKeywords are the keywords of jsonSchema.
+var schemaKeywords = map[string]bool{
+	"type": true, "enum": true, "properties": true, "required": true, "additionalProperties": true,
+	"items": true, "minItems": true, "maxItems": true, "minLength": true, "maxLength": true,
+	"pattern": true, "minimum": true, "maximum": true,
+}
+
+// schemaAnnotations are the keywords allowed in a schema which do not
+// constrain the data.
+var schemaAnnotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true,
+}
+
+// isInlineSchema reports whether value is an inline JSON Schema object
+// rather than the path of a file.
+func isInlineSchema(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "{")
+}
+
+// loadSchema parses value, an inline JSON Schema object or the path of a
+// file containing one.
+func loadSchema(value string) (*jsonSchema, error) {
+	b := []byte(value)
+	if !isInlineSchema(value) {
+		var err error
+		if b, err = ioutil.ReadFile(value); err != nil {
+			return nil, fmt.Errorf("failed to read schema file %s: %v", value, err)
+		}
+	}
+
+	s := &jsonSchema{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	if err := checkKeywords(b, ""); err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	if err := s.compile(); err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	return s, nil
+}
+
+// checkKeywords checks that the schema b at path and its subschemas only
+// use the keywords of jsonSchema, which would otherwise not be validated.
+func checkKeywords(b []byte, path string) error {
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(b, &keywords); err != nil {
+		return err
+	}
+	for name, value := range keywords {
+		switch {
+		case schemaAnnotations[name]:
+		case !schemaKeywords[name]:
+			return fmt.Errorf("unsupported keyword %s%s", path, name)
+		case name == "items":
+			if err := checkKeywords(value, path+"items."); err != nil {
+				return err
+			}
+		case name == "properties":
+			var properties map[string]json.RawMessage
+			if err := json.Unmarshal(value, &properties); err != nil {
+				return err
+			}
+			for property, schema := range properties {
+				if err := checkKeywords(schema, path+"properties."+property+"."); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// compile compiles the patterns of s and its subschemas.
+func (s *jsonSchema) compile() error {
+	if s.Pattern != "" {
+		p, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", s.Pattern, err)
+		}
+		s.pattern = p
+	}
+	for _, p := range s.Properties {
+		if err := p.compile(); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile()
+	}
+	return nil
+}
+
+// validatePayload checks the payload of the event of tick carrying data
+// conforms to the schema.
+func (a *pingAdapter) validatePayload(ctx context.Context, data string, tick templateData) error {
+	event := cloudevents.NewEvent()
+	if err := a.setEventData(ctx, &event, data, "", tick); err != nil {
+		return err
+	}
+	var v interface{}
+	if err := json.Unmarshal(event.Data(), &v); err != nil {
+		return fmt.Errorf("data does not match the schema: not JSON: %v", err)
+	}
+	if err := a.schema.validate("$", v); err != nil {
+		return fmt.Errorf("data does not match the schema: %v", err)
+	}
+	return nil
+}
+
+// validate checks the JSON value v, at path, conforms to s.
+func (s *jsonSchema) validate(path string, v interface{}) error {
+	if len(s.Type) > 0 && !s.hasType(v) {
+		return fmt.Errorf("%s: expected %s", path, strings.Join(s.Type, " or "))
+	}
+	if len(s.Enum) > 0 && !s.inEnum(v) {
+		return fmt.Errorf("%s: not one of the enumerated values", path)
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		for name, value := range v {
+			p, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fmt.Errorf("%s: unexpected property %q", path, name)
+				}
+				continue
+			}
+			if err := p.validate(path+"."+name, value); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return fmt.Errorf("%s: fewer than %d items", path, *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return fmt.Errorf("%s: more than %d items", path, *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		n := len([]rune(v))
+		if s.MinLength != nil && n < *s.MinLength {
+			return fmt.Errorf("%s: shorter than %d characters", path, *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			return fmt.Errorf("%s: longer than %d characters", path, *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return fmt.Errorf("%s: does not match pattern %q", path, s.Pattern)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return fmt.Errorf("%s: less than %v", path, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return fmt.Errorf("%s: greater than %v", path, *s.Maximum)
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) hasType(v interface{}) bool {
+	for _, t := range s.Type {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == math.Trunc(v)) {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case nil:
+			if t == "null" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *jsonSchema) inEnum(v interface{}) bool {
+	for _, e := range s.Enum {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
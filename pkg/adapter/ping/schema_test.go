@@ -0,0 +1,226 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["id", "level"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"level": {"enum": ["info", "warn"]},
+		"tags": {"type": "array", "maxItems": 2, "items": {"type": "string", "pattern": "^[a-z]+$"}}
+	}
+}`
+
+func TestSchemaValidate(t *testing.T) {
+	testCases := map[string]struct {
+		data  string
+		error bool
+	}{
+		"conforming": {
+			data: `{"id":1,"level":"info","tags":["a","b"]}`,
+		},
+		"missing required": {
+			data:  `{"id":1}`,
+			error: true,
+		},
+		"wrong type": {
+			data:  `{"id":"1","level":"info"}`,
+			error: true,
+		},
+		"not integer": {
+			data:  `{"id":1.5,"level":"info"}`,
+			error: true,
+		},
+		"below minimum": {
+			data:  `{"id":0,"level":"info"}`,
+			error: true,
+		},
+		"not in enum": {
+			data:  `{"id":1,"level":"debug"}`,
+			error: true,
+		},
+		"additional property": {
+			data:  `{"id":1,"level":"info","extra":true}`,
+			error: true,
+		},
+		"too many items": {
+			data:  `{"id":1,"level":"info","tags":["a","b","c"]}`,
+			error: true,
+		},
+		"pattern mismatch": {
+			data:  `{"id":1,"level":"info","tags":["A"]}`,
+			error: true,
+		},
+		"wrapped message": {
+			data:  "not json",
+			error: true,
+		},
+	}
+	schema, err := loadSchema(testSchema)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{schema: schema}
+			if err := a.validatePayload(context.Background(), tc.data, templateData{}); tc.error != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadSchema(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "schema.json")
+	if err := ioutil.WriteFile(path, []byte(testSchema), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		value string
+		error bool
+	}{
+		"inline": {
+			value: testSchema,
+		},
+		"file": {
+			value: path,
+		},
+		"missing file": {
+			value: filepath.Join(dir, "missing.json"),
+			error: true,
+		},
+		"invalid json": {
+			value: `{"type":`,
+			error: true,
+		},
+		"invalid pattern": {
+			value: `{"pattern":"("}`,
+			error: true,
+		},
+		"annotations": {
+			value: `{"$schema":"http://json-schema.org/draft-07/schema#","title":"ping","properties":{"id":{"description":"the id"}}}`,
+		},
+		"unsupported keyword": {
+			value: `{"type":"object","oneOf":[{"required":["id"]}]}`,
+			error: true,
+		},
+		"unsupported property keyword": {
+			value: `{"properties":{"id":{"type":"string","format":"uuid"}}}`,
+			error: true,
+		},
+		"unsupported items keyword": {
+			value: `{"items":{"uniqueItems":true}}`,
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if _, err := loadSchema(tc.value); tc.error != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEnvConfigValidateSchema(t *testing.T) {
+	env := &envConfig{
+		EnvConfig:      adapter.EnvConfig{Sink: "http://sink.example.com"},
+		Schedule:       "* * * * *",
+		Data:           `{"id":1}`,
+		ValidateSchema: `{"type":"object","anyOf":[{"required":["id"]}]}`,
+	}
+	err := env.Validate(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unsupported keyword anyOf") {
+		t.Errorf("Expected the unsupported keyword to fail Validate, got %v", err)
+	}
+}
+
+func TestStartValidateSchema(t *testing.T) {
+	testCases := map[string]struct {
+		data  string
+		error bool
+	}{
+		"conforming": {
+			data: `{"id":1,"level":"info"}`,
+		},
+		"non-conforming": {
+			data:  `{"id":1,"level":"debug"}`,
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			a := &pingAdapter{
+				Schedule:       "* * * * *",
+				Data:           tc.data,
+				ValidateSchema: testSchema,
+			}
+
+			stop := make(chan struct{})
+			close(stop)
+			if err := a.start(stop); tc.error != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateSchemaTemplate(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+
+	a := &pingAdapter{
+		Schedule:       "* * * * *",
+		Data:           `{"id":{{.Sequence}},"level":"{{if eq .Sequence 1}}info{{else}}debug{{end}}"}`,
+		DataTemplate:   true,
+		ValidateSchema: testSchema,
+		Client:         ce,
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := a.cronTick(context.Background()); err == nil {
+		t.Error("Expected an error for a non-conforming rendered template")
+	}
+	validateSent(t, ce, `{"id":1,"level":"info"}`)
+}
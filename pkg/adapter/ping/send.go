@@ -0,0 +1,215 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+)
+
+// send sends event to the adapter sink, or to each of Sinks. A failure to
+// send to one of Sinks does not prevent sending to the others. In dry run,
+// event is logged instead.
+func (a *pingAdapter) send(ctx context.Context, event cloudevents.Event) error {
+	if a.DryRun {
+		logging.FromContext(ctx).Infow("ping dry run, not sending cloudevent",
+			zap.String("id", event.ID()),
+			zap.String("type", event.Type()),
+			zap.String("source", event.Source()),
+			zap.ByteString("data", event.Data()),
+			zap.Any("extensions", event.Extensions()))
+		return nil
+	}
+
+	// A shutdown is not delayed, nor its event dropped, by the rate limit.
+	if kindFrom(ctx) != kindLifecycle {
+		if err := a.waitRate(ctx); err != nil {
+			return err
+		}
+	}
+
+	start := a.getClock().Now()
+	if event.Time().IsZero() {
+		event.SetTime(start)
+	}
+	err := a.deliver(ctx, event)
+	if err == nil {
+		recordSentID(ctx, event.ID())
+	}
+	a.recordOutcome(ctx, err == nil)
+	if a.CircuitBreakerThreshold > 0 {
+		a.breaker.record(err == nil, a.now(), a.CircuitBreakerThreshold)
+	}
+	if a.LogEvents {
+		logging.FromContext(ctx).Infow("ping sent cloudevent",
+			zap.String("id", event.ID()),
+			zap.String("type", event.Type()),
+			zap.Any("sequence", event.Extensions()[sequenceExtension]),
+			zap.Duration("latency", a.getClock().Since(start)),
+			zap.Bool("delivered", err == nil))
+	}
+	return err
+}
+
+// deliver sends event to the sink of its schedules entry, the adapter
+// sink, or each of Sinks.
+func (a *pingAdapter) deliver(ctx context.Context, event cloudevents.Event) error {
+	if entry, ok := entryFrom(ctx); ok && entry.Sink != "" {
+		ctx = cloudevents.ContextWithTarget(ctx, entry.Sink)
+	} else if len(a.Sinks) > 0 {
+		return a.deliverAll(ctx, event)
+	}
+	if err := a.sendOne(ctx, event); err != nil {
+		logging.FromContext(ctx).Errorw("ping failed to send cloudevent",
+			zap.String("errorClass", string(adapter.ClassifySendError(err))), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// deliverAll sends event to each of the Sinks, converted to their spec
+// version.
+func (a *pingAdapter) deliverAll(ctx context.Context, event cloudevents.Event) error {
+	var failed []string
+	for i, sink := range a.Sinks {
+		e := event
+		if i < len(a.SinkSpecVersions) && a.SinkSpecVersions[i] != "" && string(a.SinkSpecVersions[i]) != event.SpecVersion() {
+			e = event.Clone()
+			e.SetSpecVersion(string(a.SinkSpecVersions[i]))
+		}
+		if err := a.sendOne(cloudevents.ContextWithTarget(ctx, sink), e); err != nil {
+			logging.FromContext(ctx).Errorw("ping failed to send cloudevent", zap.String("sink", redactURL(sink)),
+				zap.String("errorClass", string(adapter.ClassifySendError(err))), zap.Error(err))
+			failed = append(failed, redactURL(sink))
+			continue
+		}
+		logging.FromContext(ctx).Debugw("ping sent cloudevent", zap.String("sink", redactURL(sink)))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to send cloudevent to %d of %d sinks: %s", len(failed), len(a.Sinks), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// sendOne sends event to the target of ctx, and records the outcome.
+// An event rejected for its encoding is sent again once with the
+// FallbackEncoding. Failed events are forwarded to the dead letter sink.
+// The reply of the target is handled when ExpectReply is set.
+func (a *pingAdapter) sendOne(ctx context.Context, event cloudevents.Event) error {
+	start := a.getClock().Now()
+	reply, result := a.requestWithRetries(ctx, event)
+	if a.FallbackEncoding != "" && isEncodingRejected(result) {
+		logging.FromContext(ctx).Infow("ping cloudevent encoding rejected, sending with the fallback encoding",
+			zap.String("encoding", string(a.FallbackEncoding)), zap.Error(result))
+		reply, result = a.requestWithRetries(contextWithEncoding(ctx, a.FallbackEncoding), event)
+		if cloudevents.IsACK(result) {
+			logging.FromContext(ctx).Infow("ping sent cloudevent with the fallback encoding",
+				zap.String("encoding", string(a.FallbackEncoding)))
+		}
+	}
+	sent := cloudevents.IsACK(result)
+	if err := a.reportEvent(adapter.ClassifySendError(result), a.getClock().Since(start)); err != nil {
+		logging.FromContext(ctx).Warnw("ping failed to report event metrics", zap.Error(err))
+	}
+	if sent && a.ExpectReply {
+		a.handleReply(ctx, reply)
+	}
+	if !sent {
+		if a.DeadLetterSink != "" {
+			a.deadLetter(ctx, event, result)
+		}
+		return result
+	}
+	return nil
+}
+
+// request sends event, as a request for a reply when ExpectReply or
+// LatencyWarnThreshold is set. Its id is the idempotency key of the
+// requests, the same on retries.
+func (a *pingAdapter) request(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+	ctx = withIdempotencyKey(ctx, event.ID())
+	if path, ok := streamFrom(ctx); ok {
+		return nil, a.sendStream(ctx, event, path)
+	}
+	if a.LatencyWarnThreshold > 0 {
+		start := a.now()
+		reply, result := a.Client.Request(ctx, event)
+		if cloudevents.IsACK(result) {
+			a.checkLatency(ctx, reply, a.getClock().Since(start))
+		}
+		return reply, result
+	}
+	if a.ExpectReply {
+		return a.Client.Request(ctx, event)
+	}
+	return nil, a.Client.Send(ctx, event)
+}
+
+// isEncodingRejected reports whether result is a 415 Unsupported Media Type
+// or 406 Not Acceptable response.
+func isEncodingRejected(result protocol.Result) bool {
+	var res *cehttp.Result
+	if !cloudevents.ResultAs(result, &res) {
+		return false
+	}
+	return res.StatusCode == http.StatusUnsupportedMediaType || res.StatusCode == http.StatusNotAcceptable
+}
+
+// deadLetter sends event, which failed to be sent to the target of ctx
+// with result, to the dead letter sink.
+func (a *pingAdapter) deadLetter(ctx context.Context, event cloudevents.Event, result protocol.Result) {
+	dest := a.Sink
+	if target := cecontext.TargetFrom(ctx); target != nil {
+		dest = redactURL(target.String())
+	}
+	event.SetExtension(errorDestExtension, dest)
+	var res *cehttp.Result
+	if cloudevents.ResultAs(result, &res) {
+		event.SetExtension(errorCodeExtension, res.StatusCode)
+	}
+
+	// The dead letter send must not be affected by the timeout of the
+	// failed send.
+	dlsCtx := trace.NewContext(context.Background(), trace.FromContext(ctx))
+	if a.SendTimeout > 0 {
+		var cancel context.CancelFunc
+		dlsCtx, cancel = context.WithTimeout(dlsCtx, a.SendTimeout)
+		defer cancel()
+	}
+	if rp := cecontext.RetriesFrom(ctx); rp != nil {
+		dlsCtx = cecontext.WithRetryParams(dlsCtx, rp)
+	}
+	dlsCtx = cloudevents.ContextWithTarget(a.withEncoding(dlsCtx), a.DeadLetterSink)
+
+	if dlsResult := a.Client.Send(dlsCtx, event); !cloudevents.IsACK(dlsResult) {
+		logging.FromContext(ctx).Errorw("ping failed to send cloudevent to the dead letter sink",
+			zap.String("sink", dest), zap.NamedError("error", result),
+			zap.String("deadLetterSink", redactURL(a.DeadLetterSink)), zap.NamedError("deadLetterError", dlsResult))
+	}
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/cloudevents/sdk-go/v2/types"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"knative.dev/pkg/logging"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestSendSinks(t *testing.T) {
+	var accepted, rejected int32
+	acceptedSink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&accepted, 1)
+		sinkAccepted(w, r)
+	}))
+	defer acceptedSink.Close()
+	rejectedSink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&rejected, 1)
+		sinkRejected(w, r)
+	}))
+	defer rejectedSink.Close()
+
+	a := &pingAdapter{
+		Data: "data",
+		// The failing sink comes first to check it doesn't prevent the delivery to the other.
+		Sinks:  []string{rejectedSink.URL, acceptedSink.URL},
+		Client: newSinkClient(t, "http://default.invalid"),
+	}
+
+	if err := a.cronTick(context.Background()); err == nil {
+		t.Error("expected error, got nil")
+	}
+	if got := atomic.LoadInt32(&rejected); got != 1 {
+		t.Errorf("Expected the failing sink to receive 1 event, got %d", got)
+	}
+	if got := atomic.LoadInt32(&accepted); got != 1 {
+		t.Errorf("Expected the accepting sink to receive 1 event, got %d", got)
+	}
+}
+
+func TestSendSinkSpecVersions(t *testing.T) {
+	type received struct{ specVersion, dataSchema, schemaURL string }
+	newSink := func() (*httptest.Server, chan received) {
+		ch := make(chan received, 1)
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ch <- received{
+				specVersion: r.Header.Get("Ce-Specversion"),
+				dataSchema:  r.Header.Get("Ce-Dataschema"),
+				schemaURL:   r.Header.Get("Ce-Schemaurl"),
+			}
+			sinkAccepted(w, r)
+		})), ch
+	}
+	oldSink, oldReceived := newSink()
+	defer oldSink.Close()
+	currentSink, currentReceived := newSink()
+	defer currentSink.Close()
+	defaultSink, defaultReceived := newSink()
+	defer defaultSink.Close()
+
+	a := &pingAdapter{
+		Schedule:   "* * * * *",
+		Data:       "data",
+		DataSchema: "https://example.com/schema.json",
+		// The last sink has no version, and gets the adapter one.
+		Sinks:            []string{oldSink.URL, currentSink.URL, defaultSink.URL},
+		SinkSpecVersions: []specVersion{"0.3", ""},
+		SpecVersion:      "1.0",
+		Client:           newSinkClient(t, "http://default.invalid"),
+	}
+	if _, _, err := a.prepare(context.Background()); err != nil {
+		t.Fatalf("prepare() = %v", err)
+	}
+
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("cronTick() = %v", err)
+	}
+	want := received{specVersion: "0.3", schemaURL: "https://example.com/schema.json"}
+	if got := <-oldReceived; got != want {
+		t.Errorf("Expected the 0.3 sink to receive %+v, got %+v", want, got)
+	}
+	want = received{specVersion: "1.0", dataSchema: "https://example.com/schema.json"}
+	if got := <-currentReceived; got != want {
+		t.Errorf("Expected the 1.0 sink to receive %+v, got %+v", want, got)
+	}
+	if got := <-defaultReceived; got != want {
+		t.Errorf("Expected the default sink to receive %+v, got %+v", want, got)
+	}
+}
+
+func TestSinkSpecVersionsDecode(t *testing.T) {
+	var got sinkSpecVersions
+	if err := got.Decode("0.3, ,1.0"); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if want := (sinkSpecVersions{"0.3", "", "1.0"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if err := got.Decode("0.3,0.2"); err == nil {
+		t.Error("Expected an error decoding spec version 0.2")
+	}
+}
+
+func TestStartBadSinkSpecVersions(t *testing.T) {
+	a := &pingAdapter{
+		Schedule:         "* * * * *",
+		Data:             "data",
+		Sinks:            []string{"http://example.com"},
+		SinkSpecVersions: []specVersion{"0.3", "1.0"},
+		Client:           adaptertest.NewTestClient(),
+	}
+	if err := a.start(make(chan struct{})); err == nil {
+		t.Error("Expected an error for more spec versions than sinks")
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	var received int32
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+		sinkAccepted(w, r)
+	}))
+	defer sink.Close()
+
+	var logs bytes.Buffer
+	ctx := withBufferLogger(context.Background(), &logs)
+
+	a := &pingAdapter{
+		Data:       `{"hello":"world"}`,
+		EventType:  "dev.knative.test",
+		Source:     "/test",
+		Extensions: map[string]string{"myext": "value"},
+		DryRun:     true,
+		Client:     newSinkClient(t, sink.URL),
+	}
+
+	if err := a.cronTick(ctx); err != nil {
+		t.Fatalf("cronTick() = %v", err)
+	}
+	if got := atomic.LoadInt32(&received); got != 0 {
+		t.Errorf("Expected the sink to receive no event, got %d", got)
+	}
+
+	var entry struct {
+		Msg        string                 `json:"msg"`
+		Type       string                 `json:"type"`
+		Source     string                 `json:"source"`
+		Data       string                 `json:"data"`
+		Extensions map[string]interface{} `json:"extensions"`
+	}
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to decode log %q: %v", logs.String(), err)
+	}
+	if entry.Type != "dev.knative.test" || entry.Source != "/test" {
+		t.Errorf("Expected the logged event of type dev.knative.test from /test, got %q from %q", entry.Type, entry.Source)
+	}
+	if entry.Data != `{"hello":"world"}` {
+		t.Errorf("Expected the logged data %q, got %q", `{"hello":"world"}`, entry.Data)
+	}
+	if got := entry.Extensions["myext"]; got != "value" {
+		t.Errorf("Expected the logged extension myext value, got %v", got)
+	}
+}
+
+func TestLogEvents(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			var logs bytes.Buffer
+			ctx := withBufferLogger(context.Background(), &logs)
+
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Data:      "data",
+				EventType: "dev.knative.test",
+				LogEvents: enabled,
+				Client:    ce,
+			}
+			if err := a.cronTick(ctx); err != nil {
+				t.Fatalf("cronTick() = %v", err)
+			}
+
+			if !enabled {
+				if logs.Len() != 0 {
+					t.Errorf("Expected no log, got %q", logs.String())
+				}
+				return
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+				t.Fatalf("failed to decode log %q: %v", logs.String(), err)
+			}
+			if got, want := entry["id"], ce.Sent()[0].ID(); got != want {
+				t.Errorf("Expected the logged id %q, got %v", want, got)
+			}
+			if got := entry["type"]; got != "dev.knative.test" {
+				t.Errorf("Expected the logged type dev.knative.test, got %v", got)
+			}
+			if got := entry["sequence"]; got != float64(1) {
+				t.Errorf("Expected the logged sequence 1, got %v", got)
+			}
+			if _, ok := entry["latency"]; !ok {
+				t.Error("Expected the latency to be logged")
+			}
+		})
+	}
+}
+
+func TestFallbackEncoding(t *testing.T) {
+	testCases := map[string]struct {
+		encoding  encoding
+		fallback  encoding
+		accept    encoding
+		wantSends int
+		wantErr   bool
+	}{
+		"binary to structured": {
+			encoding:  encodingBinary,
+			fallback:  encodingStructured,
+			accept:    encodingStructured,
+			wantSends: 2,
+		},
+		"structured to binary": {
+			encoding:  encodingStructured,
+			fallback:  encodingBinary,
+			accept:    encodingBinary,
+			wantSends: 2,
+		},
+		"primary accepted": {
+			encoding:  encodingBinary,
+			fallback:  encodingStructured,
+			accept:    encodingBinary,
+			wantSends: 1,
+		},
+		"no fallback": {
+			encoding:  encodingBinary,
+			accept:    encodingStructured,
+			wantSends: 1,
+			wantErr:   true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var sends int32
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&sends, 1)
+				got := encodingStructured
+				if r.Header.Get("Ce-Specversion") != "" {
+					got = encodingBinary
+				}
+				if got != tc.accept {
+					w.WriteHeader(http.StatusUnsupportedMediaType)
+					return
+				}
+				sinkAccepted(w, r)
+			}))
+			defer sink.Close()
+
+			a := &pingAdapter{
+				Data:             "data",
+				Encoding:         tc.encoding,
+				FallbackEncoding: tc.fallback,
+				Client:           newSinkClient(t, sink.URL),
+			}
+			if err := a.cronTick(context.Background()); tc.wantErr != (err != nil) {
+				t.Errorf("cronTick() = %v, wantErr %t", err, tc.wantErr)
+			}
+			if got := atomic.LoadInt32(&sends); got != int32(tc.wantSends) {
+				t.Errorf("Expected %d sends, got %d", tc.wantSends, got)
+			}
+		})
+	}
+}
+
+func TestDeadLetterSink(t *testing.T) {
+	testCases := map[string]struct {
+		deadLetterSink func(http.ResponseWriter, *http.Request)
+	}{
+		"dead letter sink accepted": {
+			deadLetterSink: sinkAccepted,
+		},
+		"dead letter sink rejected": {
+			deadLetterSink: sinkRejected,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer sink.Close()
+
+			received := make(chan cloudevents.Event, 1)
+			dls := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				event, err := binding.ToEvent(r.Context(), cehttp.NewMessageFromHttpRequest(r))
+				if err != nil {
+					t.Errorf("dead letter sink received an invalid event: %v", err)
+				} else {
+					received <- *event
+				}
+				tc.deadLetterSink(w, r)
+			}))
+			defer dls.Close()
+
+			a := &pingAdapter{
+				Data:           "data",
+				Sink:           sink.URL,
+				DeadLetterSink: dls.URL,
+				Client:         newSinkClient(t, sink.URL),
+			}
+
+			if err := a.cronTick(context.Background()); err == nil {
+				t.Error("expected error, got nil")
+			}
+
+			select {
+			case event := <-received:
+				if got := event.Extensions()[errorDestExtension]; got != sink.URL {
+					t.Errorf("Expected %s %q, got %v", errorDestExtension, sink.URL, got)
+				}
+				if got, err := types.ToInteger(event.Extensions()[errorCodeExtension]); err != nil || got != http.StatusServiceUnavailable {
+					t.Errorf("Expected %s %d, got %v", errorCodeExtension, http.StatusServiceUnavailable, event.Extensions()[errorCodeExtension])
+				}
+			default:
+				t.Error("Expected the dead letter sink to receive the event")
+			}
+		})
+	}
+}
+
+func TestStartBadSinks(t *testing.T) {
+	a := &pingAdapter{
+		Schedule: "* * * * *",
+		Sinks:    []string{"http://example.com", "not/absolute"},
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+// withBufferLogger returns ctx carrying a logger writing JSON info logs to
+// logs.
+func withBufferLogger(ctx context.Context, logs *bytes.Buffer) context.Context {
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(logs), zap.InfoLevel)
+	return logging.WithLogger(ctx, zap.New(core).Sugar())
+}
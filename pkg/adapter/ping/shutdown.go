@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// shutdownSignalNames are the signals SHUTDOWN_SIGNALS can list. SIGHUP
+// reloads the schedule.
+var shutdownSignalNames = map[string]os.Signal{
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGTERM": syscall.SIGTERM,
+}
+
+// shutdownSignals are the signals triggering a graceful shutdown, in
+// addition to the SIGTERM and SIGINT of the adapter framework.
+type shutdownSignals []os.Signal
+
+// Decode implements envconfig.Decoder
+func (s *shutdownSignals) Decode(value string) error {
+	var signals shutdownSignals
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !strings.HasPrefix(name, "SIG") {
+			name = "SIG" + name
+		}
+		sig, ok := shutdownSignalNames[name]
+		if !ok {
+			return fmt.Errorf("invalid shutdown signal %q: must be SIGINT, SIGQUIT or SIGTERM", name)
+		}
+		signals = append(signals, sig)
+	}
+	*s = signals
+	return nil
+}
+
+// notifyShutdown calls cancel once one of the ShutdownSignals is received,
+// or ctx is done. The returned func stops the notifications.
+func (a *pingAdapter) notifyShutdown(ctx context.Context, cancel context.CancelFunc) func() {
+	if len(a.ShutdownSignals) == 0 {
+		return func() {}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, a.ShutdownSignals...)
+	go func() {
+		select {
+		case sig := <-ch:
+			logging.FromContext(ctx).Infow("ping received a shutdown signal", zap.Stringer("signal", sig))
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return func() { signal.Stop(ch) }
+}
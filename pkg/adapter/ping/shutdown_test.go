@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestShutdownSignalsDecode(t *testing.T) {
+	testCases := map[string]struct {
+		value string
+		want  shutdownSignals
+		error bool
+	}{
+		"names": {
+			value: "SIGQUIT,SIGTERM",
+			want:  shutdownSignals{syscall.SIGQUIT, syscall.SIGTERM},
+		},
+		"short lowercase names": {
+			value: "quit, int",
+			want:  shutdownSignals{syscall.SIGQUIT, syscall.SIGINT},
+		},
+		"reload signal": {
+			value: "SIGHUP",
+			error: true,
+		},
+		"unknown signal": {
+			value: "SIGBOGUS",
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got shutdownSignals
+			err := got.Decode(tc.value)
+			if (err != nil) != tc.error {
+				t.Fatalf("Decode(%q) = %v, wanted error %t", tc.value, err, tc.error)
+			}
+			if !tc.error && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStartShutdownSignal(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Schedule:        "@every 1s",
+		Data:            "data",
+		ShutdownSignals: []os.Signal{syscall.SIGQUIT},
+		Client:          ce,
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return a.isReady(), nil
+	}); err != nil {
+		t.Fatal("the cron did not start")
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGQUIT); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("start did not return after the shutdown signal")
+	}
+
+	// The cron is stopped, no more ticks are sent.
+	sent := len(ce.Sent())
+	time.Sleep(1500 * time.Millisecond)
+	if got := len(ce.Sent()); got != sent {
+		t.Errorf("Expected no event after the shutdown, got %d", got-sent)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// signatureHeader is the header of the HMAC-SHA256 signature of the
+// request bodies.
+const signatureHeader = "X-Ping-Signature"
+
+// sign returns the signature of body with secret: sha256= followed by the
+// hex encoded HMAC-SHA256 of body keyed with secret.
+func sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signatureTransport sets the signature of the request bodies sent with
+// next in the X-Ping-Signature header. The signed bytes are the body of
+// the HTTP request exactly as sent, compressed when COMPRESSION is set:
+// the event data in binary encoding, or the JSON of the whole event in
+// structured encoding. The attributes sent as headers in binary encoding
+// are not signed.
+type signatureTransport struct {
+	next   http.RoundTripper
+	secret []byte
+}
+
+func (t *signatureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// RoundTrippers must not modify the request.
+	req = req.Clone(req.Context())
+	req.Header.Set(signatureHeader, sign(t.secret, body))
+	if body != nil {
+		req.ContentLength = int64(len(body))
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	return t.next.RoundTrip(req)
+}
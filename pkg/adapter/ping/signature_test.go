@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+)
+
+func TestSign(t *testing.T) {
+	got := sign([]byte("secret"), []byte(`{"body":"data"}`))
+	if want := "sha256=49a736df3707972455b640d27b49ba4d5b136fa51616b3c0b7e4ebb58c5b8601"; got != want {
+		t.Errorf("Expected signature %q, got %q", want, got)
+	}
+}
+
+func TestSignatureTransport(t *testing.T) {
+	testCases := map[string]struct {
+		env  *envConfig
+		want string
+	}{
+		"no secret": {
+			env: &envConfig{},
+		},
+		"secret": {
+			env:  &envConfig{HMACSecret: "secret"},
+			want: "sha256=49a736df3707972455b640d27b49ba4d5b136fa51616b3c0b7e4ebb58c5b8601",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got string
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get(signatureHeader)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer sink.Close()
+
+			a := &pingAdapter{
+				Data:   "data",
+				Client: newTransportClient(t, sink.URL, tc.env.WrapRoundTripper(http.DefaultTransport)),
+			}
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected signature %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSignatureCompressed(t *testing.T) {
+	var signature, want string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body: %v", err)
+		}
+		signature, want = r.Header.Get(signatureHeader), sign([]byte("secret"), body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer sink.Close()
+
+	env := &envConfig{HMACSecret: "secret", Compression: compressionGzip}
+	a := &pingAdapter{
+		Data:   "data",
+		Client: newTransportClient(t, sink.URL, env.WrapRoundTripper(http.DefaultTransport)),
+	}
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if signature != want {
+		t.Errorf("Expected the signature of the compressed body %q, got %q", want, signature)
+	}
+}
+
+func TestSignatureDataStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for n, secret := range map[string]string{"no secret": "", "secret": "secret"} {
+		t.Run(n, func(t *testing.T) {
+			env := &envConfig{
+				EnvConfig:    adapter.EnvConfig{Sink: "http://sink.example.com"},
+				Schedule:     "* * * * *",
+				DataFromFile: path,
+				DataStream:   true,
+				HMACSecret:   secret,
+			}
+			err := env.Validate(context.Background())
+			if wantErr := secret != ""; wantErr != (err != nil) {
+				t.Errorf("Validate() = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GetSink returns K_SINK or, when empty, the URI of the Service referenced
+// by SINK_SERVICE_NAME in SINK_SERVICE_NAMESPACE, defaulting to the
+// namespace of the adapter.
+//
+// The credentials of a K_SINK carrying userinfo are removed, they are sent
+// in the Authorization header of the requests instead.
+func (e *envConfig) GetSink() string {
+	if e.Sink != "" || e.SinkServiceName == "" {
+		sink, _ := splitUserinfo(e.Sink)
+		return sink
+	}
+	namespace := e.SinkServiceNamespace
+	if namespace == "" {
+		namespace = e.Namespace
+	}
+	return fmt.Sprintf("http://%s.%s.svc/%s", e.SinkServiceName, namespace, strings.TrimPrefix(e.SinkServicePath, "/"))
+}
+
+// validateSink checks exactly one of K_SINK and SINK_SERVICE_NAME is set,
+// unless the events are sent to SINKS.
+func (e *envConfig) validateSink() error {
+	switch {
+	case e.Sink != "" && e.SinkServiceName != "":
+		return errors.New("K_SINK and SINK_SERVICE_NAME are mutually exclusive")
+	case e.Sink == "" && e.SinkServiceName == "" && len(e.Sinks) == 0:
+		return errors.New("one of K_SINK or SINK_SERVICE_NAME must be set")
+	case e.SinkServiceName == "" && (e.SinkServiceNamespace != "" || e.SinkServicePath != ""):
+		return errors.New("SINK_SERVICE_NAMESPACE and SINK_SERVICE_PATH require SINK_SERVICE_NAME")
+	}
+	return nil
+}
+
+// splitUserinfo returns sink without its userinfo, and the userinfo if any.
+func splitUserinfo(sink string) (string, *url.Userinfo) {
+	u, err := url.Parse(sink)
+	if err != nil || u.User == nil {
+		return sink, nil
+	}
+	user := u.User
+	u.User = nil
+	return u.String(), user
+}
+
+// redactURL returns s with the password of its userinfo, if any, redacted
+// so that it can be logged.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// scheduledTimeExtension is the CloudEvent extension carrying the
+	// time the tick was scheduled at.
+	scheduledTimeExtension = "scheduledtime"
+
+	// firedTimeExtension is the CloudEvent extension carrying the time
+	// the event was sent at.
+	firedTimeExtension = "firedtime"
+)
+
+// timeSource is what the time attribute of the events is.
+type timeSource string
+
+const (
+	// timeSourceNow is the time the event is sent at.
+	timeSourceNow timeSource = "now"
+	// timeSourceScheduled is the time the tick was scheduled at.
+	timeSourceScheduled timeSource = "scheduled"
+)
+
+// Decode implements envconfig.Decoder
+func (s *timeSource) Decode(value string) error {
+	switch source := timeSource(value); source {
+	case timeSourceNow, timeSourceScheduled:
+		*s = source
+		return nil
+	default:
+		return fmt.Errorf("invalid time source %q: must be %s or %s", value, timeSourceNow, timeSourceScheduled)
+	}
+}
+
+type slotKey struct{}
+
+// withSlot returns ctx of the tick scheduled at slot.
+func withSlot(ctx context.Context, slot time.Time) context.Context {
+	return context.WithValue(ctx, slotKey{}, slot)
+}
+
+// slotFrom returns the time the tick of ctx was scheduled at, if known.
+func slotFrom(ctx context.Context) (time.Time, bool) {
+	slot, ok := ctx.Value(slotKey{}).(time.Time)
+	return slot, ok && !slot.IsZero()
+}
+
+// slots records the activation times the cron computes for its entry.
+type slots struct {
+	mu         sync.Mutex
+	prev, next time.Time
+}
+
+// slotSchedule is a cron.Schedule recording its activation times in slots.
+type slotSchedule struct {
+	cron.Schedule
+	slots *slots
+}
+
+// Next implements cron.Schedule
+func (s slotSchedule) Next(t time.Time) time.Time {
+	next := s.Schedule.Next(t)
+	s.slots.mu.Lock()
+	defer s.slots.mu.Unlock()
+	s.slots.prev, s.slots.next = s.slots.next, next
+	return next
+}
+
+// track returns sched recording its activation times in s.
+func (s *slots) track(sched cron.Schedule) cron.Schedule {
+	return slotSchedule{Schedule: sched, slots: s}
+}
+
+// fired returns the activation time of the tick firing at now. The cron
+// starts the job before computing the next activation time, which is then
+// after now.
+func (s *slots) fired(now time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.next.After(now) {
+		return s.next
+	}
+	return s.prev
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/types"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestScheduledAndFiredTime(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Schedule:  "@every 1s",
+		Data:      "data",
+		JitterMax: 200 * time.Millisecond,
+		Client:    ce,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(ce.Sent()) >= 2, nil
+	}); err != nil {
+		t.Fatal("Expected 2 events to be sent")
+	}
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for _, event := range ce.Sent() {
+		scheduled, err := types.ToTime(event.Extensions()[scheduledTimeExtension])
+		if err != nil {
+			t.Fatalf("Expected the %s extension, got %v", scheduledTimeExtension, err)
+		}
+		fired, err := types.ToTime(event.Extensions()[firedTimeExtension])
+		if err != nil {
+			t.Fatalf("Expected the %s extension, got %v", firedTimeExtension, err)
+		}
+		if fired.Before(scheduled) {
+			t.Errorf("Expected %s %v not before %s %v", firedTimeExtension, fired, scheduledTimeExtension, scheduled)
+		}
+		// The ticks of @every 1s are scheduled on whole seconds.
+		if scheduled.Nanosecond() != 0 {
+			t.Errorf("Expected %s %v on a whole second", scheduledTimeExtension, scheduled)
+		}
+	}
+}
+
+func TestSlotsFired(t *testing.T) {
+	sched, err := cron.ParseStandard("* * * * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+	var s slots
+	tracked := s.track(sched)
+
+	start := time.Date(2020, 8, 10, 9, 30, 20, 0, time.UTC)
+	slot := tracked.Next(start)
+
+	// The job started before the cron computed the next activation time.
+	firing := slot.Add(10 * time.Millisecond)
+	if got := s.fired(firing); !got.Equal(slot) {
+		t.Errorf("Expected the slot %v before the next activation is computed, got %v", slot, got)
+	}
+
+	// The job started after.
+	tracked.Next(slot)
+	if got := s.fired(firing); !got.Equal(slot) {
+		t.Errorf("Expected the slot %v after the next activation is computed, got %v", slot, got)
+	}
+}
+
+func TestTimeSource(t *testing.T) {
+	slot := time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC)
+	// The tick was delayed after its slot, by jitter or queueing.
+	now := slot.Add(2 * time.Second)
+
+	testCases := map[string]struct {
+		source timeSource
+		want   time.Time
+	}{
+		"default": {
+			want: now,
+		},
+		"now": {
+			source: timeSourceNow,
+			want:   now,
+		},
+		"scheduled": {
+			source: timeSourceScheduled,
+			want:   slot,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Data:       "data",
+				TimeSource: tc.source,
+				Client:     ce,
+				clock:      clock.NewFakeClock(now),
+			}
+
+			if err := a.cronTick(withSlot(context.Background(), slot)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := ce.Sent()[0].Time(); !got.Equal(tc.want) {
+				t.Errorf("Expected the time %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
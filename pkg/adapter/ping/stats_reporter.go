@@ -0,0 +1,232 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ping
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricskey"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+)
+
+var (
+	// eventsSentM is a counter which records the number of events
+	// successfully sent by the PingSource.
+	eventsSentM = stats.Int64(
+		"events_sent_total",
+		"Number of events successfully sent by the PingSource",
+		stats.UnitDimensionless,
+	)
+
+	// eventsFailedM is a counter which records the number of events
+	// the PingSource failed to send.
+	eventsFailedM = stats.Int64(
+		"events_failed_total",
+		"Number of events the PingSource failed to send",
+		stats.UnitDimensionless,
+	)
+
+	// eventsDroppedM is a counter which records the number of ticks
+	// dropped because the send queue was full.
+	eventsDroppedM = stats.Int64(
+		"events_dropped_total",
+		"Number of ticks the PingSource dropped because its send queue was full",
+		stats.UnitDimensionless,
+	)
+
+	// scheduleDriftM is a gauge which records how late the last tick of
+	// the PingSource fired after its scheduled time.
+	scheduleDriftM = stats.Float64(
+		"schedule_drift_seconds",
+		"Delay of the last tick of the PingSource after its scheduled time",
+		stats.UnitSeconds,
+	)
+
+	// lastSuccessTimeM is a gauge which records the time, in seconds since
+	// the epoch, of the last event successfully sent by the PingSource.
+	lastSuccessTimeM = stats.Float64(
+		"last_success_time",
+		"Time of the last event successfully sent by the PingSource, in seconds since the epoch",
+		stats.UnitSeconds,
+	)
+
+	// lastFailureTimeM is a gauge which records the time, in seconds since
+	// the epoch, of the last event the PingSource failed to send.
+	lastFailureTimeM = stats.Float64(
+		"last_failure_time",
+		"Time of the last event the PingSource failed to send, in seconds since the epoch",
+		stats.UnitSeconds,
+	)
+
+	// sendDurationM is a histogram which records the duration of the
+	// sends of the PingSource, retries included.
+	sendDurationM = stats.Float64(
+		"send_duration_seconds",
+		"Duration of the sends of the PingSource, retries included",
+		stats.UnitSeconds,
+	)
+
+	// sendDurationBuckets are the bucket bounds of sendDurationM, from
+	// 5ms to 30s.
+	sendDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+	// Create the tag keys that will be used to add tags to our measurements.
+	namespaceKey  = tag.MustNewKey(metricskey.LabelNamespaceName)
+	nameKey       = tag.MustNewKey(metricskey.LabelName)
+	outcomeKey    = tag.MustNewKey("outcome")
+	errorClassKey = tag.MustNewKey("error_class")
+
+	registerOnce sync.Once
+)
+
+// registerViews registers the PingSource views, which are exported by the
+// adapter metrics exporter, prefixed with the component name.
+func registerViews() {
+	registerOnce.Do(register)
+}
+
+func register() {
+	tagKeys := []tag.Key{
+		namespaceKey,
+		nameKey,
+	}
+
+	err := metrics.RegisterResourceView(
+		&view.View{
+			Description: eventsSentM.Description(),
+			Measure:     eventsSentM,
+			Aggregation: view.Count(),
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Description: eventsFailedM.Description(),
+			Measure:     eventsFailedM,
+			Aggregation: view.Count(),
+			TagKeys:     append(tagKeys, errorClassKey),
+		},
+		&view.View{
+			Description: eventsDroppedM.Description(),
+			Measure:     eventsDroppedM,
+			Aggregation: view.Count(),
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Description: scheduleDriftM.Description(),
+			Measure:     scheduleDriftM,
+			Aggregation: view.LastValue(),
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Description: lastSuccessTimeM.Description(),
+			Measure:     lastSuccessTimeM,
+			Aggregation: view.LastValue(),
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Description: lastFailureTimeM.Description(),
+			Measure:     lastFailureTimeM,
+			Aggregation: view.LastValue(),
+			TagKeys:     tagKeys,
+		},
+		&view.View{
+			Description: sendDurationM.Description(),
+			Measure:     sendDurationM,
+			Aggregation: view.Distribution(sendDurationBuckets...),
+			TagKeys:     append(tagKeys, outcomeKey),
+		},
+	)
+	if err != nil {
+		log.Printf("failed to register opencensus views, %s", err)
+	}
+}
+
+// reportEvent records an event successfully sent, or failed to be sent
+// with the class of its failure, and the duration of its send.
+func (a *pingAdapter) reportEvent(class adapter.SendErrorClass, duration time.Duration) error {
+	ctx, err := a.generateTag()
+	if err != nil {
+		return err
+	}
+	outcome := "success"
+	if class == "" {
+		metrics.Record(ctx, eventsSentM.M(1))
+	} else {
+		outcome = "failure"
+		failedCtx, err := tag.New(ctx, tag.Insert(errorClassKey, string(class)))
+		if err != nil {
+			return err
+		}
+		metrics.Record(failedCtx, eventsFailedM.M(1))
+	}
+
+	ctx, err = tag.New(ctx, tag.Insert(outcomeKey, outcome))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, sendDurationM.M(duration.Seconds()))
+	return nil
+}
+
+// reportDropped records a tick dropped from the full send queue.
+func (a *pingAdapter) reportDropped() error {
+	ctx, err := a.generateTag()
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, eventsDroppedM.M(1))
+	return nil
+}
+
+// reportDrift records the drift of the last tick.
+func (a *pingAdapter) reportDrift(drift time.Duration) error {
+	ctx, err := a.generateTag()
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, scheduleDriftM.M(drift.Seconds()))
+	return nil
+}
+
+// reportOutcomeTime records the time of the last successful, or failed,
+// send.
+func (a *pingAdapter) reportOutcomeTime(sent bool, t time.Time) error {
+	ctx, err := a.generateTag()
+	if err != nil {
+		return err
+	}
+	seconds := float64(t.UnixNano()) / float64(time.Second)
+	if sent {
+		metrics.Record(ctx, lastSuccessTimeM.M(seconds))
+	} else {
+		metrics.Record(ctx, lastFailureTimeM.M(seconds))
+	}
+	return nil
+}
+
+func (a *pingAdapter) generateTag() (context.Context, error) {
+	return tag.New(
+		context.Background(),
+		tag.Insert(namespaceKey, a.Namespace),
+		tag.Insert(nameKey, a.Name))
+}
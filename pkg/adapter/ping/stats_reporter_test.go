@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package ping
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats/view"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestReportEvent(t *testing.T) {
+	resetMetrics()
+
+	s := httptest.NewServer(http.HandlerFunc(sinkRejected))
+	defer s.Close()
+
+	wantTags := map[string]string{
+		metricskey.LabelNamespaceName: "testns",
+		metricskey.LabelName:          "testname",
+	}
+
+	a := &pingAdapter{
+		Data:      "data",
+		Name:      "testname",
+		Namespace: "testns",
+		Client:    newSinkClient(t, s.URL),
+	}
+
+	failedTags := map[string]string{
+		metricskey.LabelNamespaceName: "testns",
+		metricskey.LabelName:          "testname",
+		"error_class":                 "4xx",
+	}
+
+	if err := a.cronTick(context.Background()); err == nil {
+		t.Fatal("expected the send to fail")
+	}
+	metricstest.CheckCountData(t, "events_failed_total", failedTags, 1)
+	metricstest.CheckStatsNotReported(t, "events_sent_total")
+
+	a.Client = adaptertest.NewTestClient()
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	metricstest.CheckCountData(t, "events_sent_total", wantTags, 1)
+	metricstest.CheckCountData(t, "events_failed_total", failedTags, 1)
+}
+
+func TestReportSendDuration(t *testing.T) {
+	resetMetrics()
+
+	a := &pingAdapter{
+		Data:      "data",
+		Name:      "testname",
+		Namespace: "testns",
+		Client:    adaptertest.NewTestClientWithDelay(50 * time.Millisecond),
+	}
+
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	metricstest.CheckDistributionCount(t, "send_duration_seconds", map[string]string{
+		metricskey.LabelNamespaceName: "testns",
+		metricskey.LabelName:          "testname",
+		"outcome":                     "success",
+	}, 1)
+
+	rows, err := view.RetrieveData("send_duration_seconds")
+	if err != nil {
+		t.Fatalf("failed to retrieve send_duration_seconds: %v", err)
+	}
+	if got := rows[0].Data.(*view.DistributionData).Min; got < 0.05 {
+		t.Errorf("Expected a send duration of at least 50ms, got %vs", got)
+	}
+}
+
+func resetMetrics() {
+	// OpenCensus metrics carry global state that need to be reset between unit tests.
+	metricstest.Unregister(
+		"events_sent_total",
+		"events_failed_total",
+		"events_dropped_total",
+		"schedule_drift_seconds",
+		"last_success_time",
+		"last_failure_time",
+		"send_duration_seconds")
+	register()
+}
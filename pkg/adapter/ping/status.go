@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"knative.dev/pkg/logging"
+)
+
+// sendStatus is the body returned by the /status endpoint. The times are
+// omitted until a send succeeded, or failed.
+type sendStatus struct {
+	Sequence        int32      `json:"sequence"`
+	LastSuccessTime *time.Time `json:"lastSuccessTime,omitempty"`
+	LastFailureTime *time.Time `json:"lastFailureTime,omitempty"`
+}
+
+// recordOutcome records the time of a successful, or failed, send.
+func (a *pingAdapter) recordOutcome(ctx context.Context, sent bool) {
+	now := a.now()
+	a.mu.Lock()
+	if sent {
+		a.lastSuccess = now
+	} else {
+		a.lastFailure = now
+	}
+	a.mu.Unlock()
+	if err := a.reportOutcomeTime(sent, now); err != nil {
+		logging.FromContext(ctx).Warnw("ping failed to report the send time", zap.Error(err))
+	}
+}
+
+// status returns the sequence number and the times of the last sends.
+func (a *pingAdapter) status() sendStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := sendStatus{Sequence: a.sequence}
+	if !a.lastSuccess.IsZero() {
+		t := a.lastSuccess
+		s.LastSuccessTime = &t
+	}
+	if !a.lastFailure.IsZero() {
+		t := a.lastFailure
+		s.LastFailureTime = &t
+	}
+	return s
+}
+
+// statusHandler serves the status of the sends, for alerting on the
+// last successful send being too old.
+func (a *pingAdapter) statusHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a.status())
+}
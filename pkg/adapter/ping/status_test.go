@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestSendStatus(t *testing.T) {
+	resetMetrics()
+	wantTags := map[string]string{
+		metricskey.LabelNamespaceName: "testns",
+		metricskey.LabelName:          "testname",
+	}
+
+	var reject int32
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&reject) == 1 {
+			sinkRejected(w, r)
+			return
+		}
+		sinkAccepted(w, r)
+	}))
+	defer sink.Close()
+
+	success := time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC)
+	failure := success.Add(time.Minute)
+	fakeClock := clock.NewFakeClock(success)
+	a := &pingAdapter{
+		Data:      "data",
+		Name:      "testname",
+		Namespace: "testns",
+		Client:    newSinkClient(t, sink.URL),
+		clock:     fakeClock,
+	}
+	srv := httptest.NewServer(a.probeHandler())
+	defer srv.Close()
+	getStatus := func() sendStatus {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/status")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var got sendStatus
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if got := getStatus(); got.Sequence != 0 || got.LastSuccessTime != nil || got.LastFailureTime != nil {
+		t.Errorf("Expected no send in the status, got %+v", got)
+	}
+
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("cronTick() = %v", err)
+	}
+	got := getStatus()
+	if got.Sequence != 1 || got.LastSuccessTime == nil || !got.LastSuccessTime.Equal(success) || got.LastFailureTime != nil {
+		t.Errorf("Expected the sequence 1 and last success at %v, got %+v", success, got)
+	}
+	metricstest.CheckLastValueData(t, "last_success_time", wantTags, float64(success.Unix()))
+
+	atomic.StoreInt32(&reject, 1)
+	fakeClock.SetTime(failure)
+	if err := a.cronTick(context.Background()); err == nil {
+		t.Fatal("Expected an error sending to the rejecting sink")
+	}
+	got = getStatus()
+	if got.Sequence != 2 || got.LastSuccessTime == nil || !got.LastSuccessTime.Equal(success) ||
+		got.LastFailureTime == nil || !got.LastFailureTime.Equal(failure) {
+		t.Errorf("Expected the sequence 2, last success at %v and last failure at %v, got %+v", success, failure, got)
+	}
+	metricstest.CheckLastValueData(t, "last_success_time", wantTags, float64(success.Unix()))
+	metricstest.CheckLastValueData(t, "last_failure_time", wantTags, float64(failure.Unix()))
+}
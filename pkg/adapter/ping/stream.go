@@ -0,0 +1,158 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+)
+
+// defaultRetryableStatus are the status the client retries on, which the
+// adapter retries streamed sends on when RetryableStatus is not set.
+var defaultRetryableStatus = retryableStatus{"404", "425", "429", "503", "504"}
+
+// validateStream checks the settings DataStream is used with. The
+// streamed data is sent as is, in binary encoding.
+func (a *pingAdapter) validateStream() error {
+	switch {
+	case a.DataFromFile == "":
+		return errors.New("DATA_STREAM requires DATA_FROM_FILE")
+	case a.DataTemplate || a.Batch || a.DataBase64 || a.ValidateSchema != "":
+		return errors.New("DATA_STREAM is mutually exclusive with DATA_TEMPLATE, BATCH, DATA_BASE64 and VALIDATE_SCHEMA")
+	case a.Payload != nil:
+		return errors.New("DATA_STREAM is mutually exclusive with PAYLOAD_PROVIDER")
+	case a.ExpectReply:
+		return errors.New("DATA_STREAM is mutually exclusive with EXPECT_REPLY")
+	case a.Encoding == encodingStructured || a.FallbackEncoding == encodingStructured:
+		return errors.New("DATA_STREAM requires the binary encoding")
+	case a.MaxPayloadAction == payloadActionTruncate && jsonContentType(a.DataContentType):
+		return fmt.Errorf("DATA_STREAM cannot truncate the JSON of DATA_CONTENT_TYPE %s", a.DataContentType)
+	}
+	return nil
+}
+
+type streamKey struct{}
+
+// withStream returns ctx of a tick whose data is streamed from the file
+// at path.
+func withStream(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, streamKey{}, path)
+}
+
+// streamFrom returns the path of the file the data of the tick of ctx is
+// streamed from, if any.
+func streamFrom(ctx context.Context) (string, bool) {
+	path, ok := ctx.Value(streamKey{}).(string)
+	return path, ok
+}
+
+// sendStream sends event with the content of the file at path as its
+// data, read while the request is written. Every call opens the file
+// again, so that a retry sends the whole data. Without a stream sender,
+// such as for a file sink, the file is read in memory and sent with the
+// client instead. The data is truncated to the MaxPayloadBytes with the
+// truncate action.
+func (a *pingAdapter) sendStream(ctx context.Context, event cloudevents.Event, path string) error {
+	if a.stream == nil {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read data file %s: %v", path, err)
+		}
+		if max := a.truncatedSize(); max > 0 && len(b) > max {
+			b = b[:max]
+		}
+		if err := event.SetData(event.DataContentType(), b); err != nil {
+			return err
+		}
+		return a.Client.Send(ctx, event)
+	}
+	return a.stream.Send(ctx, &streamMessage{event: event, path: path, limit: int64(a.truncatedSize()), open: a.openStream})
+}
+
+// streamMessage is a binary binding.Message of event whose data is read
+// from the file at path, opened when the message is written. At most
+// limit bytes are read, when positive.
+type streamMessage struct {
+	event cloudevents.Event
+	path  string
+	limit int64
+	// open opens path, os.Open when nil.
+	open func(path string) (io.ReadCloser, error)
+
+	data io.ReadCloser
+}
+
+var (
+	_ binding.Message               = (*streamMessage)(nil)
+	_ binding.MessageMetadataReader = (*streamMessage)(nil)
+)
+
+// ReadEncoding implements binding.MessageReader
+func (m *streamMessage) ReadEncoding() binding.Encoding {
+	return binding.EncodingBinary
+}
+
+// ReadStructured implements binding.MessageReader
+func (m *streamMessage) ReadStructured(context.Context, binding.StructuredWriter) error {
+	return binding.ErrNotStructured
+}
+
+// ReadBinary implements binding.MessageReader
+func (m *streamMessage) ReadBinary(ctx context.Context, w binding.BinaryWriter) error {
+	if err := binding.ToMessage(&m.event).ReadBinary(ctx, w); err != nil {
+		return err
+	}
+	open := m.open
+	if open == nil {
+		open = func(path string) (io.ReadCloser, error) { return os.Open(path) }
+	}
+	data, err := open(m.path)
+	if err != nil {
+		return fmt.Errorf("failed to read data file %s: %v", m.path, err)
+	}
+	m.data = data
+	if m.limit > 0 {
+		return w.SetData(io.LimitReader(data, m.limit))
+	}
+	return w.SetData(data)
+}
+
+// GetAttribute implements binding.MessageMetadataReader
+func (m *streamMessage) GetAttribute(k spec.Kind) (spec.Attribute, interface{}) {
+	return (*binding.EventMessage)(&m.event).GetAttribute(k)
+}
+
+// GetExtension implements binding.MessageMetadataReader
+func (m *streamMessage) GetExtension(name string) interface{} {
+	return (*binding.EventMessage)(&m.event).GetExtension(name)
+}
+
+// Finish implements binding.Message
+func (m *streamMessage) Finish(error) error {
+	if m.data == nil {
+		return nil
+	}
+	return m.data.Close()
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"go.opencensus.io/trace"
+
+	"knative.dev/eventing/pkg/adapter/v2"
+)
+
+// countingReader counts the bytes read from r, and the most read at once.
+type countingReader struct {
+	r       io.ReadCloser
+	mu      *sync.Mutex
+	read    *int
+	maxRead *int
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.mu.Lock()
+	*c.read += n
+	if n > *c.maxRead {
+		*c.maxRead = n
+	}
+	c.mu.Unlock()
+	return n, err
+}
+
+func (c countingReader) Close() error {
+	return c.r.Close()
+}
+
+func TestDataStream(t *testing.T) {
+	const size = 16 << 20
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+	dir, err := ioutil.TempDir("", "stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu       sync.Mutex
+		requests int
+		got      [sha256.Size]byte
+		header   http.Header
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			// The first request fails, the retry must send the whole data.
+			io.CopyN(ioutil.Discard, r.Body, size/2)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		h := sha256.New()
+		io.Copy(h, r.Body)
+		copy(got[:], h.Sum(nil))
+		header = r.Header
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	stream, err := cehttp.New(cehttp.WithTarget(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		readMu        sync.Mutex
+		opens         int
+		read, maxRead int
+	)
+	a := &pingAdapter{
+		Schedule:     "* * * * *",
+		DataFromFile: path,
+		DataStream:   true,
+		RetryCount:   2,
+		RetryBackoff: time.Millisecond,
+		Client:       newSinkClient(t, s.URL),
+		stream:       stream,
+		openStream: func(path string) (io.ReadCloser, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			readMu.Lock()
+			opens++
+			read = 0
+			readMu.Unlock()
+			return countingReader{r: f, mu: &readMu, read: &read, maxRead: &maxRead}, nil
+		},
+	}
+	if _, _, err := a.prepare(context.Background()); err != nil {
+		t.Fatalf("prepare() = %v", err)
+	}
+	if a.fileData != "" {
+		t.Error("Expected the streamed data not to be read in memory")
+	}
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("cronTick() = %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+	if opens != 2 {
+		t.Errorf("Expected the data file opened 2 times, got %d", opens)
+	}
+	if read != size {
+		t.Errorf("Expected %d bytes read, got %d", size, read)
+	}
+	if maxRead >= size/16 {
+		t.Errorf("Expected the data file read in chunks, read %d bytes at once", maxRead)
+	}
+	if want := sha256.Sum256(data); !bytes.Equal(got[:], want[:]) {
+		t.Error("Expected the data delivered intact")
+	}
+	if got, want := header.Get("Content-Type"), octetStream; got != want {
+		t.Errorf("Expected content type %q, got %q", want, got)
+	}
+	if header.Get("Ce-Id") == "" {
+		t.Error("Expected the event attributes in the headers")
+	}
+}
+
+func TestDataStreamTracing(t *testing.T) {
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(1e-4)})
+
+	dir, err := ioutil.TempDir("", "stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu          sync.Mutex
+		traceparent []string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		traceparent = append(traceparent, r.Header.Get("traceparent"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	defaultTransport := http.DefaultClient.Transport
+	env := &envConfig{
+		EnvConfig:    adapter.EnvConfig{Sink: s.URL},
+		Schedule:     "* * * * *",
+		DataFromFile: path,
+		DataStream:   true,
+	}
+	ce := newTransportClient(t, s.URL, env.WrapRoundTripper(baseTransport()))
+	a := NewAdapter(context.Background(), env, ce).(*pingAdapter)
+	if http.DefaultClient.Transport != defaultTransport {
+		t.Error("Expected the data stream not to change the transport of http.DefaultClient")
+	}
+	if _, _, err := a.prepare(context.Background()); err != nil {
+		t.Fatalf("prepare() = %v", err)
+	}
+
+	// The streamed tick, then a lifecycle event sent with the client.
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("cronTick() = %v", err)
+	}
+	a.sendLifecycleEvent(context.Background(), startedEventType)
+
+	if len(traceparent) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(traceparent))
+	}
+	for i, tp := range traceparent {
+		if tp == "" {
+			t.Errorf("Expected request %d to carry a traceparent", i)
+		}
+	}
+}
+
+func TestDataStreamValidation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testCases := map[string]*pingAdapter{
+		"no data file":     {Schedule: "* * * * *", Data: "data", DataStream: true},
+		"missing file":     {Schedule: "* * * * *", DataFromFile: path + ".missing", DataStream: true},
+		"template":         {Schedule: "* * * * *", DataFromFile: path, DataStream: true, DataTemplate: true},
+		"batch":            {Schedule: "* * * * *", DataFromFile: path, DataStream: true, Batch: true},
+		"expect reply":     {Schedule: "* * * * *", DataFromFile: path, DataStream: true, ExpectReply: true},
+		"structured":       {Schedule: "* * * * *", DataFromFile: path, DataStream: true, Encoding: encodingStructured},
+		"payload provider": {Schedule: "* * * * *", DataFromFile: path, DataStream: true, Payload: EnvPayload{Data: "data"}},
+		"truncated json": {Schedule: "* * * * *", DataFromFile: path, DataStream: true, DataContentType: "application/json",
+			MaxPayloadBytes: 2, MaxPayloadAction: payloadActionTruncate},
+	}
+	ok := &pingAdapter{Schedule: "* * * * *", DataFromFile: path, DataStream: true}
+	if _, _, err := ok.prepare(context.Background()); err != nil {
+		t.Errorf("prepare() = %v", err)
+	}
+	for n, a := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if _, _, err := a.prepare(context.Background()); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestDataStreamCompression(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []compression{compressionNone, compressionGzip} {
+		t.Run(string(c), func(t *testing.T) {
+			env := &envConfig{
+				EnvConfig:    adapter.EnvConfig{Sink: "http://sink.example.com"},
+				Schedule:     "* * * * *",
+				DataFromFile: path,
+				DataStream:   true,
+				Compression:  c,
+			}
+			err := env.Validate(context.Background())
+			if wantErr := c == compressionGzip; wantErr != (err != nil) {
+				t.Errorf("Validate() = %v", err)
+			}
+		})
+	}
+}
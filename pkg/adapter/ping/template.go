@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+// templateData is the data the data template is executed with.
+type templateData struct {
+	// Time is the time of the tick.
+	Time time.Time
+
+	// Name is the name of the adapter.
+	Name string
+
+	// Namespace is the namespace of the adapter.
+	Namespace string
+
+	// Sequence is the sequence number of the event.
+	Sequence int32
+
+	// Replay is true when the tick was missed while the adapter was down.
+	Replay bool
+
+	// kind is how the event of the tick is emitted.
+	kind pingKind
+
+	// correlationID is the id of the tick, in its events and log lines.
+	correlationID string
+}
+
+// dataTemplate returns the parsed template of text. The template is only
+// parsed again when text changes.
+func (a *pingAdapter) dataTemplate(text string) (*template.Template, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.tmpl == nil || a.tmplText != text {
+		t, err := template.New("data").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data template: %v", err)
+		}
+		a.tmpl, a.tmplText = t, text
+	}
+	return a.tmpl, nil
+}
+
+// render executes the data template text with td.
+func (a *pingAdapter) render(text string, td templateData) (string, error) {
+	t, err := a.dataTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	view := templateView{templateData: td, Time: templateTime{Time: a.localTime(td.Time), layout: a.timeFormat()}}
+	if err := t.Execute(&b, view); err != nil {
+		return "", fmt.Errorf("failed to execute data template: %v", err)
+	}
+	return b.String(), nil
+}
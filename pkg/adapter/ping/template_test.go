@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestDataTemplate(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	now := time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC)
+
+	a := &pingAdapter{
+		Schedule:     "* * * * *",
+		Data:         `{"firedAt":"{{.Time.Format "2006-01-02T15:04:05Z07:00"}}","from":"{{.Namespace}}/{{.Name}}","sequence":{{.Sequence}}}`,
+		DataTemplate: true,
+		Name:         "name",
+		Namespace:    "ns",
+		Client:       ce,
+		clock:        clock.NewFakeClock(now),
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a.cronTick(context.Background())
+	validateSent(t, ce, `{"firedAt":"2020-08-10T09:30:00Z","from":"ns/name","sequence":1}`)
+}
+
+func TestDataTemplateNotEnabled(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+
+	a := &pingAdapter{
+		Data:   `{"firedAt":"{{.Time}}"}`,
+		Client: ce,
+	}
+
+	a.cronTick(context.Background())
+	validateSent(t, ce, `{"firedAt":"{{.Time}}"}`)
+}
+
+func TestStartBadDataTemplate(t *testing.T) {
+	a := &pingAdapter{
+		Schedule:     "* * * * *",
+		Data:         `{"firedAt":"{{.Time"}`,
+		DataTemplate: true,
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
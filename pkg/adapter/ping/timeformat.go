@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"fmt"
+	"time"
+)
+
+// templateTime is the time of a tick in templates. It prints with the
+// TimeFormat, while keeping the methods of time.Time such as Format and
+// Unix.
+type templateTime struct {
+	time.Time
+	layout string
+}
+
+// String implements fmt.Stringer
+func (t templateTime) String() string {
+	return t.Format(t.layout)
+}
+
+// templateView is the templateData a template is executed with, with the
+// Time rendered with the TimeFormat.
+type templateView struct {
+	templateData
+	Time templateTime
+}
+
+// validateTimeFormat returns an error when layout has no time element,
+// which would render every time as the layout itself.
+func validateTimeFormat(layout string) error {
+	if layout == "" {
+		return nil
+	}
+	if time.Unix(0, 0).UTC().Format(layout) == layout {
+		return fmt.Errorf("invalid time format %q: must contain a Go layout element such as 2006 or 15:04", layout)
+	}
+	return nil
+}
+
+// timeFormat returns the layout times are rendered with.
+func (a *pingAdapter) timeFormat() string {
+	if a.TimeFormat == "" {
+		return time.RFC3339
+	}
+	return a.TimeFormat
+}
+
+// localTime returns t in the zone times are rendered in: UTC, or the
+// schedule time zone with TimeLocal.
+func (a *pingAdapter) localTime(t time.Time) time.Time {
+	if !a.TimeLocal {
+		return t.UTC()
+	}
+	if a.Timezone != "" {
+		if loc, err := time.LoadLocation(a.Timezone); err == nil {
+			return t.In(loc)
+		}
+	}
+	return t.Local()
+}
+
+// formatTime renders t with the TimeFormat in the configured zone.
+func (a *pingAdapter) formatTime(t time.Time) string {
+	return a.localTime(t).Format(a.timeFormat())
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestTimeFormat(t *testing.T) {
+	now := time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		format    string
+		local     bool
+		timezone  string
+		want      string
+		extension string
+	}{
+		"default": {
+			want:      `{"firedAt":"2020-08-10T09:30:00Z"}`,
+			extension: "2020-08-10T09:30:00Z",
+		},
+		"layout": {
+			format:    "02 Jan 06 15:04 MST",
+			want:      `{"firedAt":"10 Aug 20 09:30 UTC"}`,
+			extension: "10 Aug 20 09:30 UTC",
+		},
+		"utc with timezone": {
+			timezone:  "America/New_York",
+			want:      `{"firedAt":"2020-08-10T09:30:00Z"}`,
+			extension: "2020-08-10T09:30:00Z",
+		},
+		"local": {
+			format:    "2006-01-02 15:04 -0700",
+			local:     true,
+			timezone:  "America/New_York",
+			want:      `{"firedAt":"2020-08-10 05:30 -0400"}`,
+			extension: "2020-08-10 05:30 -0400",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ce := adaptertest.NewTestClient()
+			a := &pingAdapter{
+				Schedule:     "* * * * *",
+				Data:         `{"firedAt":"{{.Time}}"}`,
+				DataTemplate: true,
+				Timezone:     tc.timezone,
+				TimeFormat:   tc.format,
+				TimeLocal:    tc.local,
+				Client:       ce,
+				clock:        clock.NewFakeClock(now),
+			}
+
+			stop := make(chan struct{})
+			close(stop)
+			if err := a.start(stop); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			a.cronTick(context.Background())
+			validateSent(t, ce, tc.want)
+
+			event := ce.Sent()[0]
+			for _, name := range []string{scheduledTimeExtension, firedTimeExtension} {
+				if got := event.Extensions()[name]; got != tc.extension {
+					t.Errorf("Expected %s %q, got %v", name, tc.extension, got)
+				}
+			}
+		})
+	}
+}
+
+func TestStartBadTimeFormat(t *testing.T) {
+	a := &pingAdapter{
+		Schedule:   "* * * * *",
+		TimeFormat: "yyyy-MM-dd",
+	}
+
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	ceextensions "github.com/cloudevents/sdk-go/v2/extensions"
+	"go.opencensus.io/trace"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (e *recordingExporter) ExportSpan(s *trace.SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, s)
+}
+
+func (e *recordingExporter) Spans() []*trace.SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*trace.SpanData(nil), e.spans...)
+}
+
+func TestTracing(t *testing.T) {
+	exporter := &recordingExporter{}
+	trace.RegisterExporter(exporter)
+	defer trace.UnregisterExporter(exporter)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(1e-4)})
+
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:      "data",
+		Name:      "name",
+		Namespace: "ns",
+		Client:    ce,
+	}
+
+	a.cronTick(context.Background())
+	a.cronTick(context.Background())
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+
+	sent := ce.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 events to be sent, got %d", len(sent))
+	}
+	for i, event := range sent {
+		if got, want := spans[i].Name, "pingsource:name.ns"; got != want {
+			t.Errorf("Expected span %q, got %q", want, got)
+		}
+		dt, ok := ceextensions.GetDistributedTracingExtension(event)
+		if !ok {
+			t.Fatalf("Expected event %d to carry a traceparent", i)
+		}
+		sc, err := dt.ToSpanContext()
+		if err != nil {
+			t.Fatalf("invalid traceparent: %v", err)
+		}
+		if sc.TraceID != spans[i].TraceID {
+			t.Errorf("Expected trace ID %v, got %v", spans[i].TraceID, sc.TraceID)
+		}
+	}
+}
+
+func TestTracingNotSampled(t *testing.T) {
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(1e-4)})
+
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		Data:   "data",
+		Client: ce,
+	}
+
+	a.cronTick(context.Background())
+	if _, ok := ceextensions.GetDistributedTracingExtension(ce.Sent()[0]); ok {
+		t.Error("Expected no traceparent when tracing is not sampled")
+	}
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/plugin/ochttp"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/tracing/propagation/tracecontextb3"
+)
+
+// baseTransport is the transport the CloudEvents client of the adapter
+// sends with, which WrapRoundTripper is called with.
+func baseTransport() http.RoundTripper {
+	return &ochttp.Transport{
+		Propagation: tracecontextb3.TraceContextEgress,
+	}
+}
+
+// WrapRoundTripper implements adapter.RoundTripperWrapper
+func (e *envConfig) WrapRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	wrapped, err := e.wrapRoundTripper(rt)
+	if err != nil {
+		// Validate checks the wrapping of the baseTransport, this is only
+		// reached with another one.
+		return errorTransport{err: err}
+	}
+	return wrapped
+}
+
+// wrapRoundTripper wraps rt with the transports of the configuration.
+func (e *envConfig) wrapRoundTripper(rt http.RoundTripper) (http.RoundTripper, error) {
+	// The TLS configuration applies to the base transport, wrapped next.
+	if e.TLSClientCert != "" || e.TLSClientKey != "" || e.TLSCACert != "" {
+		cfg, err := loadTLSConfig(e.TLSClientCert, e.TLSClientKey, e.TLSCACert)
+		if err != nil {
+			return nil, err
+		}
+		if rt, err = withTLS(rt, cfg); err != nil {
+			return nil, err
+		}
+	}
+	userAgent := e.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent()
+	}
+	rt = &userAgentTransport{next: rt, userAgent: userAgent}
+	if sink, user := splitUserinfo(e.Sink); user != nil {
+		if u, err := url.Parse(sink); err == nil {
+			rt = &basicAuthTransport{next: rt, host: u.Host, user: user}
+		}
+	}
+	if e.IdempotencyKey {
+		rt = &idempotencyTransport{next: rt}
+	}
+	if len(e.Headers) > 0 {
+		rt = &headerTransport{next: rt, headers: http.Header(e.Headers)}
+	}
+	if e.AuthTokenFile != "" {
+		t, err := newTokenTransport(rt, e.AuthTokenFile, e.AuthTokenRefresh)
+		if err != nil {
+			return nil, err
+		}
+		rt = t
+	}
+	if e.HMACSecret != "" {
+		// The signature is of the body as sent, after the compression.
+		rt = &signatureTransport{next: rt, secret: []byte(e.HMACSecret)}
+	}
+	if e.Compression == compressionGzip {
+		rt = &gzipTransport{next: rt}
+	}
+	return rt, nil
+}
+
+// basicAuthTransport sets the basic authentication of user on the
+// requests to host sent with next, unless they carry an Authorization
+// header already.
+type basicAuthTransport struct {
+	next http.RoundTripper
+	host string
+	user *url.Userinfo
+}
+
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host || req.Header.Get("Authorization") != "" {
+		return t.next.RoundTrip(req)
+	}
+	// RoundTrippers must not modify the request.
+	req = req.Clone(req.Context())
+	password, _ := t.user.Password()
+	req.SetBasicAuth(t.user.Username(), password)
+	return t.next.RoundTrip(req)
+}
+
+// defaultUserAgent is the User-Agent of the requests without USER_AGENT.
+func defaultUserAgent() string {
+	return "knative-pingsource/" + version
+}
+
+// userAgentTransport sets userAgent as the User-Agent header of the
+// requests sent with next, unless set already, such as with HEADERS.
+type userAgentTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") != "" {
+		return t.next.RoundTrip(req)
+	}
+	// RoundTrippers must not modify the request.
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.next.RoundTrip(req)
+}
+
+// headers are static HTTP headers decoded from newline or comma-separated
+// Key:Value pairs.
+type headers http.Header
+
+// Decode implements envconfig.Decoder
+func (h *headers) Decode(value string) error {
+	hs := http.Header{}
+	for _, pair := range strings.FieldsFunc(value, func(r rune) bool { return r == '\n' || r == ',' }) {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		i := strings.Index(pair, ":")
+		if i < 0 {
+			return fmt.Errorf("invalid header %q: must be Key:Value", pair)
+		}
+		key, val := strings.TrimSpace(pair[:i]), strings.TrimSpace(pair[i+1:])
+		if !isValidHeaderName(key) {
+			return fmt.Errorf("invalid header name %q", key)
+		}
+		hs.Add(key, val)
+	}
+	*h = headers(hs)
+	return nil
+}
+
+// isValidHeaderName checks name is an HTTP token.
+func isValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// headerTransport sets headers on the requests sent with next.
+type headerTransport struct {
+	next    http.RoundTripper
+	headers http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the request.
+	req = req.Clone(req.Context())
+	for key, values := range t.headers {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// tokenTransport sets the bearer token read from a file as the
+// Authorization header of the requests sent with next. The file is read
+// again when the token is older than refresh, so that rotated tokens are
+// picked up.
+type tokenTransport struct {
+	next    http.RoundTripper
+	file    string
+	refresh time.Duration
+	clock   Clock
+
+	mu     sync.Mutex
+	token  string
+	readAt time.Time
+}
+
+func newTokenTransport(next http.RoundTripper, file string, refresh time.Duration) (*tokenTransport, error) {
+	t := &tokenTransport{
+		next:    next,
+		file:    file,
+		refresh: refresh,
+		clock:   clock.RealClock{},
+	}
+	if err := t.read(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// read reads the token from the file.
+func (t *tokenTransport) read() error {
+	b, err := ioutil.ReadFile(t.file)
+	if err != nil {
+		return fmt.Errorf("failed to read auth token file %s: %v", t.file, err)
+	}
+	t.token = strings.TrimSpace(string(b))
+	t.readAt = t.clock.Now()
+	return nil
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	if t.clock.Since(t.readAt) >= t.refresh {
+		if err := t.read(); err != nil {
+			logging.FromContext(req.Context()).Warnw("ping failed to refresh the auth token, using the previous one", zap.Error(err))
+		}
+	}
+	token := t.token
+	t.mu.Unlock()
+
+	// RoundTrippers must not modify the request.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.next.RoundTrip(req)
+}
+
+// loadTLSConfig returns the TLS configuration presenting the certificate
+// certFile and its key keyFile, and trusting the certificates of caFile.
+// Each file is optional.
+func loadTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("TLS_CLIENT_CERT and TLS_CLIENT_KEY must be set together")
+	}
+
+	cfg := &tls.Config{}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		b, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate %s: %v", caFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no CA certificate found in %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// withTLS returns rt sending the requests with the TLS configuration cfg.
+// Only an http.Transport, possibly traced with an ochttp.Transport, can
+// be configured.
+func withTLS(rt http.RoundTripper, cfg *tls.Config) (http.RoundTripper, error) {
+	switch t := rt.(type) {
+	case *ochttp.Transport:
+		base := t.Base
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		base, err := withTLS(base, cfg)
+		if err != nil {
+			return nil, err
+		}
+		traced := *t
+		traced.Base = base
+		return &traced, nil
+	case *http.Transport:
+		t = t.Clone()
+		t.TLSClientConfig = cfg
+		return t, nil
+	default:
+		return nil, fmt.Errorf("cannot configure TLS on %T", rt)
+	}
+}
+
+// errorTransport fails every request with err.
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
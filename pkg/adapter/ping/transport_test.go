@@ -0,0 +1,405 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/plugin/ochttp"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// writePEM writes the PEM block of type typ and content b to path.
+func writePEM(t *testing.T, path, typ string, b []byte) {
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: b}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeClientCert writes a self-signed client certificate and its key to
+// dir, returning their paths and the certificate.
+func writeClientCert(t *testing.T, dir string) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ping"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile, cert
+}
+
+func TestTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, clientCert := writeClientCert(t, dir)
+
+	sink := httptest.NewUnstartedServer(http.HandlerFunc(sinkAccepted))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	sink.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	sink.StartTLS()
+	defer sink.Close()
+
+	caFile := filepath.Join(dir, "ca.crt")
+	writePEM(t, caFile, "CERTIFICATE", sink.Certificate().Raw)
+
+	testCases := map[string]struct {
+		env   *envConfig
+		error bool
+	}{
+		"client certificate": {
+			env: &envConfig{TLSClientCert: certFile, TLSClientKey: keyFile, TLSCACert: caFile},
+		},
+		"client certificate and headers": {
+			env: &envConfig{TLSClientCert: certFile, TLSClientKey: keyFile, TLSCACert: caFile, Headers: headers{"X-Tenant-Id": {"acme"}}},
+		},
+		"no client certificate": {
+			env:   &envConfig{TLSCACert: caFile},
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			rt := tc.env.WrapRoundTripper(&ochttp.Transport{})
+			ce := newTransportClient(t, sink.URL, rt)
+
+			a := &pingAdapter{
+				Data:   "data",
+				Client: ce,
+			}
+			if err := a.cronTick(context.Background()); tc.error != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, _ := writeClientCert(t, dir)
+	missing := filepath.Join(dir, "missing")
+
+	testCases := map[string]struct {
+		cert, key, ca string
+		error         bool
+	}{
+		"cert and key": {
+			cert: certFile,
+			key:  keyFile,
+		},
+		"certificate as CA": {
+			ca: certFile,
+		},
+		"cert without key": {
+			cert:  certFile,
+			error: true,
+		},
+		"missing cert": {
+			cert:  missing,
+			key:   keyFile,
+			error: true,
+		},
+		"missing CA": {
+			ca:    missing,
+			error: true,
+		},
+		"CA without certificate": {
+			ca:    keyFile,
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if _, err := loadTLSConfig(tc.cert, tc.key, tc.ca); tc.error != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestWithTLS(t *testing.T) {
+	testCases := map[string]struct {
+		rt    http.RoundTripper
+		error bool
+	}{
+		"transport": {
+			rt: &http.Transport{},
+		},
+		"traced default transport": {
+			rt: &ochttp.Transport{},
+		},
+		"traced transport": {
+			rt: &ochttp.Transport{Base: &http.Transport{}},
+		},
+		"other transport": {
+			rt:    &headerTransport{next: http.DefaultTransport},
+			error: true,
+		},
+		"traced other transport": {
+			rt:    &ochttp.Transport{Base: &headerTransport{next: http.DefaultTransport}},
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if _, err := withTLS(tc.rt, &tls.Config{}); tc.error != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTokenTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+
+	if _, err := newTokenTransport(http.DefaultTransport, tokenFile, time.Minute); err == nil {
+		t.Error("Expected an error for a missing token file")
+	}
+
+	if err := ioutil.WriteFile(tokenFile, []byte("first\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var auth string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		sinkAccepted(w, r)
+	}))
+	defer sink.Close()
+
+	rt, err := newTokenTransport(http.DefaultTransport, tokenFile, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fc := clock.NewFakeClock(rt.readAt)
+	rt.clock = fc
+	ce := newTransportClient(t, sink.URL, rt)
+	a := &pingAdapter{
+		Data:   "data",
+		Client: ce,
+	}
+
+	steps := []struct {
+		name   string
+		token  string
+		remove bool
+		step   time.Duration
+		want   string
+	}{{
+		name: "initial token",
+		want: "Bearer first",
+	}, {
+		name:  "rotated before refresh",
+		token: "second",
+		want:  "Bearer first",
+	}, {
+		name: "rotated after refresh",
+		step: time.Minute,
+		want: "Bearer second",
+	}, {
+		name:   "removed",
+		remove: true,
+		step:   time.Minute,
+		want:   "Bearer second",
+	}}
+	for _, s := range steps {
+		if s.token != "" {
+			if err := ioutil.WriteFile(tokenFile, []byte(s.token), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if s.remove {
+			os.Remove(tokenFile)
+		}
+		fc.Step(s.step)
+
+		if err := a.cronTick(context.Background()); err != nil {
+			t.Fatalf("%s: unexpected error: %v", s.name, err)
+		}
+		if auth != s.want {
+			t.Errorf("%s: expected Authorization %q, got %q", s.name, s.want, auth)
+		}
+	}
+}
+
+func TestHeadersDecode(t *testing.T) {
+	testCases := map[string]struct {
+		value string
+		want  headers
+		error bool
+	}{
+		"comma separated": {
+			value: "X-Tenant-ID: acme,X-Route:blue",
+			want:  headers{"X-Tenant-Id": {"acme"}, "X-Route": {"blue"}},
+		},
+		"newline separated": {
+			value: "X-Tenant-ID: acme\nX-Route: blue\n",
+			want:  headers{"X-Tenant-Id": {"acme"}, "X-Route": {"blue"}},
+		},
+		"value with colon": {
+			value: "X-Target: http://example.com",
+			want:  headers{"X-Target": {"http://example.com"}},
+		},
+		"missing colon": {
+			value: "X-Tenant-ID acme",
+			error: true,
+		},
+		"empty name": {
+			value: ": acme",
+			error: true,
+		},
+		"invalid name": {
+			value: "X Tenant: acme",
+			error: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got headers
+			err := got.Decode(tc.value)
+			if tc.error != (err != nil) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !tc.error {
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("unexpected headers (-want, +got) = %v", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	var tenant string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Tenant-ID")
+		sinkAccepted(w, r)
+	}))
+	defer sink.Close()
+
+	env := &envConfig{Headers: headers{"X-Tenant-Id": {"acme"}}}
+	ce := newTransportClient(t, sink.URL, env.WrapRoundTripper(http.DefaultTransport))
+
+	a := &pingAdapter{
+		Data:   "data",
+		Client: ce,
+	}
+	if err := a.cronTick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tenant != "acme" {
+		t.Errorf("Expected X-Tenant-ID header acme, got %q", tenant)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	testCases := map[string]struct {
+		env  *envConfig
+		want string
+	}{
+		"default": {
+			env:  &envConfig{},
+			want: "knative-pingsource/" + version,
+		},
+		"configured": {
+			env:  &envConfig{UserAgent: "acme-ping/1.0"},
+			want: "acme-ping/1.0",
+		},
+		"header": {
+			env:  &envConfig{UserAgent: "acme-ping/1.0", Headers: headers{"User-Agent": {"acme-header/1.0"}}},
+			want: "acme-header/1.0",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got string
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+				sinkAccepted(w, r)
+			}))
+			defer sink.Close()
+
+			a := &pingAdapter{
+				Data:   "data",
+				Client: newTransportClient(t, sink.URL, tc.env.WrapRoundTripper(http.DefaultTransport)),
+			}
+			if err := a.cronTick(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected User-Agent %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// dataVariant is one of the payloads sent in turn, picked with a
+// probability proportional to its weight.
+type dataVariant struct {
+	// Data is the data of the variant. A JSON string is unquoted, any
+	// other JSON value is sent as is.
+	Data string
+
+	// Weight is the positive relative weight of the variant.
+	Weight float64
+
+	// ContentType overrides the DataContentType when set.
+	ContentType string
+}
+
+// dataVariants is a JSON array of {"data": ..., "weight": ...} objects,
+// with an optional "contentType".
+type dataVariants []dataVariant
+
+// Decode implements envconfig.Decoder
+func (v *dataVariants) Decode(value string) error {
+	var raw []struct {
+		Data        json.RawMessage `json:"data"`
+		Weight      float64         `json:"weight"`
+		ContentType string          `json:"contentType"`
+	}
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return fmt.Errorf("invalid data variants: must be a JSON array of {data, weight} objects: %v", err)
+	}
+	if len(raw) == 0 {
+		return errors.New("invalid data variants: must not be empty")
+	}
+
+	variants := make(dataVariants, len(raw))
+	for i, r := range raw {
+		if r.Weight <= 0 {
+			return fmt.Errorf("invalid weight %v of data variant %d: must be positive", r.Weight, i)
+		}
+		if len(r.Data) == 0 {
+			return fmt.Errorf("invalid data variant %d: missing data", i)
+		}
+		variants[i].Weight = r.Weight
+		variants[i].ContentType = r.ContentType
+		if err := json.Unmarshal(r.Data, &variants[i].Data); err != nil {
+			variants[i].Data = string(r.Data)
+		}
+	}
+	*v = variants
+	return nil
+}
+
+// pickVariant returns one of DataVariants, picked at random by weight.
+// a.mu must be held.
+func (a *pingAdapter) pickVariant() dataVariant {
+	var total float64
+	for _, v := range a.DataVariants {
+		total += v.Weight
+	}
+
+	var r float64
+	if a.rnd != nil {
+		r = a.rnd.Float64() * total
+	} else {
+		r = rand.Float64() * total
+	}
+	for _, v := range a.DataVariants {
+		if r < v.Weight {
+			return v
+		}
+		r -= v.Weight
+	}
+	// Rounding errors may leave r past the last weight.
+	return a.DataVariants[len(a.DataVariants)-1]
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestDataVariantsDecode(t *testing.T) {
+	testCases := map[string]struct {
+		value   string
+		want    dataVariants
+		wantErr bool
+	}{
+		"strings and objects": {
+			value: `[{"data":"plain","weight":1},{"data":{"a":1},"weight":2.5}]`,
+			want:  dataVariants{{Data: "plain", Weight: 1}, {Data: `{"a":1}`, Weight: 2.5}},
+		},
+		"content type": {
+			value: `[{"data":"a,b","weight":1,"contentType":"text/csv"}]`,
+			want:  dataVariants{{Data: "a,b", Weight: 1, ContentType: "text/csv"}},
+		},
+		"invalid json": {
+			value:   `[{"data":`,
+			wantErr: true,
+		},
+		"not an array": {
+			value:   `{"data":"plain","weight":1}`,
+			wantErr: true,
+		},
+		"empty": {
+			value:   `[]`,
+			wantErr: true,
+		},
+		"zero weight": {
+			value:   `[{"data":"plain","weight":0}]`,
+			wantErr: true,
+		},
+		"negative weight": {
+			value:   `[{"data":"plain","weight":-1}]`,
+			wantErr: true,
+		},
+		"missing data": {
+			value:   `[{"weight":1}]`,
+			wantErr: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got dataVariants
+			err := got.Decode(tc.value)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Decode() = %v, wantErr %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); !tc.wantErr && diff != "" {
+				t.Errorf("Unexpected variants (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestDataVariants(t *testing.T) {
+	const ticks = 4000
+	weights := map[string]float64{"a": 1, "b": 3, "c": 6}
+
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		DataVariants: []dataVariant{
+			{Data: `{"v":"a"}`, Weight: weights["a"]},
+			{Data: `{"v":"b"}`, Weight: weights["b"]},
+			{Data: `{"v":"c"}`, Weight: weights["c"]},
+		},
+		Client: ce,
+		rnd:    rand.New(rand.NewSource(1)),
+	}
+	for i := 0; i < ticks; i++ {
+		if err := a.cronTick(context.Background()); err != nil {
+			t.Fatalf("cronTick() = %v", err)
+		}
+	}
+
+	counts := map[string]int{}
+	for _, event := range ce.Sent() {
+		var data struct{ V string }
+		if err := json.Unmarshal(event.Data(), &data); err != nil {
+			t.Fatalf("Unexpected data %q: %v", event.Data(), err)
+		}
+		counts[data.V]++
+	}
+	for v, weight := range weights {
+		want := weight / 10
+		if got := float64(counts[v]) / ticks; math.Abs(got-want) > 0.03 {
+			t.Errorf("Expected variant %s to be sent %.2f of the time, got %.2f", v, want, got)
+		}
+	}
+}
+
+func TestDataVariantsContentType(t *testing.T) {
+	ce := adaptertest.NewTestClient()
+	a := &pingAdapter{
+		DataVariants: []dataVariant{
+			{Data: "a,b", Weight: 1, ContentType: "text/csv"},
+			{Data: `{"v":"json"}`, Weight: 1},
+		},
+		Client: ce,
+		rnd:    rand.New(rand.NewSource(1)),
+	}
+	for i := 0; i < 20; i++ {
+		if err := a.cronTick(context.Background()); err != nil {
+			t.Fatalf("cronTick() = %v", err)
+		}
+	}
+
+	want := map[string]string{"a,b": "text/csv", `{"v":"json"}`: "application/json"}
+	for _, event := range ce.Sent() {
+		if got := event.DataContentType(); got != want[string(event.Data())] {
+			t.Errorf("Expected the content type %q of %q, got %q", want[string(event.Data())], event.Data(), got)
+		}
+	}
+}
+
+func TestStartDataVariantsWithData(t *testing.T) {
+	a := &pingAdapter{
+		Schedule:     "* * * * *",
+		Data:         "data",
+		DataVariants: []dataVariant{{Data: "variant", Weight: 1}},
+	}
+	stop := make(chan struct{})
+	close(stop)
+	if err := a.start(stop); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
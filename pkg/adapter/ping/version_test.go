@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"context"
+	"testing"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestEmitVersion(t *testing.T) {
+	defer func(v string) { version = v }(version)
+	version = "v0.17.0-test"
+
+	for _, emit := range []bool{true, false} {
+		ce := adaptertest.NewTestClient()
+		a := &pingAdapter{
+			Data:        "data",
+			EmitVersion: emit,
+			Client:      ce,
+		}
+		if err := a.cronTick(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, ok := ce.Sent()[0].Extensions()[versionExtension]
+		if emit && got != "v0.17.0-test" {
+			t.Errorf("Expected the %s extension v0.17.0-test, got %v", versionExtension, got)
+		}
+		if !emit && ok {
+			t.Errorf("Expected no %s extension, got %v", versionExtension, got)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/logging"
+)
+
+// watchConfigFile sends on changes once the content of the file at path
+// changed and then stayed the same for the ConfigWatchDebounce, until ctx
+// is done, so that rapid writes result in a single change.
+func (a *pingAdapter) watchConfigFile(ctx context.Context, path string, changes chan<- struct{}) {
+	logger := logging.FromContext(ctx)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Errorw("ping failed to watch the config file", zap.Error(err))
+		return
+	}
+	defer watcher.Close()
+	// The directory is watched rather than the file, as the ConfigMap
+	// volume updates swap a symlink instead of writing the file.
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		logger.Errorw("ping failed to watch the config file", zap.Error(err))
+		return
+	}
+	a.debounceConfigEvents(ctx, path, watcher.Events, watcher.Errors, changes)
+}
+
+// debounceConfigEvents sends on changes once no events were received
+// for the ConfigWatchDebounce and the content of the file at path
+// changed, until ctx is done or events is closed.
+func (a *pingAdapter) debounceConfigEvents(ctx context.Context, path string, events <-chan fsnotify.Event, errs <-chan error, changes chan<- struct{}) {
+	logger := logging.FromContext(ctx)
+
+	last, _ := ioutil.ReadFile(path)
+	var debounce clock.Timer
+	var fire <-chan time.Time
+	defer func() {
+		if debounce != nil {
+			debounce.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-errs:
+			if !ok {
+				return
+			}
+			logger.Warnw("ping failed to watch the config file", zap.Error(err))
+			continue
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+			// Any event of the directory may change the file through a
+			// symlink, the content is compared once debounced.
+			if debounce != nil {
+				debounce.Stop()
+			}
+			debounce = a.getClock().NewTimer(a.ConfigWatchDebounce)
+			fire = debounce.C()
+			continue
+		case <-fire:
+			fire = nil
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			logger.Warnw("ping failed to read the watched config file", zap.Error(err))
+			continue
+		}
+		if bytes.Equal(b, last) {
+			continue
+		}
+		last = b
+		select {
+		case changes <- struct{}{}:
+		default:
+			// A change is pending already.
+		}
+	}
+}
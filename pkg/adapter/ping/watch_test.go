@@ -0,0 +1,210 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ping
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	adaptertest "knative.dev/eventing/pkg/adapter/v2/test"
+)
+
+func TestDebounceConfigEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("SCHEDULE: '* * * * *'")
+
+	fakeClock := clock.NewFakeClock(time.Date(2020, 8, 10, 9, 30, 0, 0, time.UTC))
+	a := &pingAdapter{ConfigWatchDebounce: 2 * time.Second, clock: fakeClock}
+	var logs bytes.Buffer
+	ctx, cancel := context.WithCancel(withBufferLogger(context.Background(), &logs))
+	defer cancel()
+	events := make(chan fsnotify.Event)
+	errs := make(chan error)
+	changes := make(chan struct{}, 1)
+	go a.debounceConfigEvents(ctx, path, events, errs, changes)
+
+	// event writes content to the file and sends its event. An error is
+	// sent next, received once the event is handled.
+	event := func(content string) {
+		t.Helper()
+		write(content)
+		events <- fsnotify.Event{Name: path, Op: fsnotify.Write}
+		errs <- errors.New("handled")
+	}
+	changed := func() bool {
+		select {
+		case <-changes:
+			return true
+		default:
+			return false
+		}
+	}
+
+	event("SCHEDULE: '*/5 * * * *'")
+	fakeClock.Step(time.Second)
+	// A rapid second write restarts the debounce.
+	event("SCHEDULE: '*/10 * * * *'")
+	fakeClock.Step(time.Second)
+	if changed() {
+		t.Fatal("Expected no change before the debounce")
+	}
+	fakeClock.Step(time.Second)
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a change once no events were received for the debounce")
+	}
+	fakeClock.Step(2 * time.Second)
+	if changed() {
+		t.Error("Expected a single change for the rapid writes")
+	}
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte("SCHEDULE: '* * * * *'"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &pingAdapter{ConfigWatchDebounce: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 1)
+	go a.watchConfigFile(ctx, path, changes)
+
+	// The file is written until the watch, started concurrently, reports
+	// the change.
+	i := 0
+	if err := wait.PollImmediate(50*time.Millisecond, 5*time.Second, func() (bool, error) {
+		i++
+		if err := ioutil.WriteFile(path, []byte(fmt.Sprintf("SCHEDULE: '*/%d * * * *'", i)), 0644); err != nil {
+			return false, err
+		}
+		select {
+		case <-changes:
+			return true, nil
+		default:
+			return false, nil
+		}
+	}); err != nil {
+		t.Fatalf("no change of the written config file: %v", err)
+	}
+}
+
+func TestStartConfigWatch(t *testing.T) {
+	defer func() {
+		configFileMu.Lock()
+		defer configFileMu.Unlock()
+		for name := range configFileVars {
+			os.Unsetenv(name)
+			delete(configFileVars, name)
+		}
+	}()
+
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("SCHEDULE: '0 0 1 1 *'\nDATA: old\n")
+	if err := loadConfigFile(path); err != nil {
+		t.Fatalf("loadConfigFile() = %v", err)
+	}
+
+	ce := adaptertest.NewTestClient()
+	changes := make(chan struct{})
+	a := &pingAdapter{
+		Schedule:      "0 0 1 1 *",
+		Data:          "old",
+		ConfigFile:    path,
+		ConfigWatch:   true,
+		Client:        ce,
+		configChanges: changes,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.start(stop)
+	}()
+
+	write("SCHEDULE: '@every 1s'\nDATA: new\n")
+	changes <- struct{}{}
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(ce.Sent()) > 0, nil
+	}); err != nil {
+		t.Fatal("no event sent on the reloaded schedule")
+	}
+	validateSent(t, ce, `{"body":"new"}`)
+
+	// An invalid update keeps the current configuration. The second
+	// change is only received once the first reload is done.
+	write("SCHEDULE: bogus\nDATA: bad\n")
+	changes <- struct{}{}
+	changes <- struct{}{}
+
+	a.mu.Lock()
+	schedule, data := a.Schedule, a.Data
+	entries := a.cron.Entries()
+	a.mu.Unlock()
+	if schedule != "@every 1s" || data != "new" {
+		t.Errorf("Expected the schedule %q and data %q to be kept, got %q and %q", "@every 1s", "new", schedule, data)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 cron entry, got %d", len(entries))
+	}
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	nethttp "net/http"
 	"net/url"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
@@ -38,13 +39,30 @@ func NewCloudEventsClient(target string, ceOverrides *duckv1.CloudEventOverrides
 	return NewCloudEventsClientCRStatus(target, ceOverrides, reporter, nil)
 }
 func NewCloudEventsClientCRStatus(target string, ceOverrides *duckv1.CloudEventOverrides, reporter source.StatsReporter, crStatusEventClient *crstatusevent.CRStatusEventClient) (cloudevents.Client, error) {
+	return newCloudEventsClient(target, ceOverrides, reporter, crStatusEventClient, nil)
+}
+
+// RoundTripperWrapper is implemented by the EnvConfigAccessors wrapping the
+// HTTP transport of the CloudEvents client, for instance to alter the
+// outbound requests.
+type RoundTripperWrapper interface {
+	WrapRoundTripper(nethttp.RoundTripper) nethttp.RoundTripper
+}
+
+func newCloudEventsClient(target string, ceOverrides *duckv1.CloudEventOverrides, reporter source.StatsReporter, crStatusEventClient *crstatusevent.CRStatusEventClient, wrapper RoundTripperWrapper) (cloudevents.Client, error) {
 	pOpts := make([]http.Option, 0)
 	if len(target) > 0 {
 		pOpts = append(pOpts, cloudevents.WithTarget(target))
 	}
-	pOpts = append(pOpts, cloudevents.WithRoundTripper(&ochttp.Transport{
+	var rt nethttp.RoundTripper = &ochttp.Transport{
 		Propagation: tracecontextb3.TraceContextEgress,
-	}))
+	}
+	if wrapper != nil {
+		rt = wrapper.WrapRoundTripper(rt)
+	}
+	// The transport is set on a client of its own, not on the shared
+	// http.DefaultClient.
+	pOpts = append(pOpts, http.WithClient(nethttp.Client{}), cloudevents.WithRoundTripper(rt))
 
 	p, err := cloudevents.NewHTTP(pOpts...)
 	if err != nil {
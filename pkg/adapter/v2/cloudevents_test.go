@@ -18,6 +18,8 @@ package adapter
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
@@ -150,6 +152,57 @@ func TestNewCloudEventsClient_request(t *testing.T) {
 	}
 }
 
+type headerWrapper struct{}
+
+func (headerWrapper) WrapRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.Header.Set("X-Wrapped", "true")
+		return rt.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewCloudEventsClient_roundTripperWrapper(t *testing.T) {
+	var wrapped string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wrapped = r.Header.Get("X-Wrapped")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer sink.Close()
+
+	ceClient, err := newCloudEventsClient(sink.URL, nil, &mockReporter{}, nil, headerWrapper{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := cloudevents.NewEvent()
+	event.SetID("abc-123")
+	event.SetSource("unit/test")
+	event.SetType("unit.type")
+	if result := ceClient.Send(context.TODO(), event); !cloudevents.IsACK(result) {
+		t.Fatal(result)
+	}
+	if wrapped != "true" {
+		t.Errorf("Expected the request to go through the wrapped round tripper")
+	}
+
+	wrapped = ""
+	resp, err := http.Get(sink.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if wrapped != "" {
+		t.Errorf("Expected http.DefaultClient not to go through the wrapped round tripper")
+	}
+}
+
 func validateSent(t *testing.T, ce *test.TestCloudEventsClient, want string) {
 	if got := len(ce.Sent()); got != 1 {
 		t.Errorf("Expected 1 event to be sent, got %d", got)
@@ -16,6 +16,7 @@ limitations under the License.
 package adapter
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -43,6 +44,8 @@ const (
 	EnvConfigLoggingConfig        = "K_LOGGING_CONFIG"
 	EnvConfigTracingConfig        = "K_TRACING_CONFIG"
 	EnvConfigLeaderElectionConfig = "K_LEADER_ELECTION_CONFIG"
+	EnvConfigRestartBackoffMax    = "RESTART_BACKOFF_MAX"
+	EnvConfigRestartStablePeriod  = "RESTART_STABLE_PERIOD"
 )
 
 // EnvConfig is the minimal set of configuration parameters
@@ -85,6 +88,15 @@ type EnvConfig struct {
 
 	// LeaderElectionConfigJson is the leader election component configuration.
 	LeaderElectionConfigJson string `envconfig:"K_LEADER_ELECTION_CONFIG"`
+
+	// RestartBackoffMax is the maximum exponential backoff between the
+	// restarts of an adapter whose Start returns an error.
+	// Zero disables the restarts.
+	RestartBackoffMax time.Duration `envconfig:"RESTART_BACKOFF_MAX"`
+
+	// RestartStablePeriod is how long an adapter must run before failing
+	// for its restart backoff to be reset.
+	RestartStablePeriod time.Duration `envconfig:"RESTART_STABLE_PERIOD" default:"1m"`
 }
 
 // EnvConfigAccessor defines accessors for the minimal
@@ -116,7 +128,23 @@ type EnvConfigAccessor interface {
 	GetLeaderElectionConfig() (*kle.ComponentConfig, error)
 }
 
+// EnvConfigValidator is implemented by the EnvConfigAccessors validating
+// their configuration. Validate is called once the environment variables
+// are processed, before the adapter is constructed.
+type EnvConfigValidator interface {
+	Validate(ctx context.Context) error
+}
+
+// EnvConfigRestarter is implemented by the EnvConfigAccessors restarting
+// the failing adapters. GetRestartBackoff returns the maximum backoff
+// between restarts, and the period after which the backoff is reset. The
+// adapter is not restarted when max is zero.
+type EnvConfigRestarter interface {
+	GetRestartBackoff() (max, stable time.Duration)
+}
+
 var _ EnvConfigAccessor = (*EnvConfig)(nil)
+var _ EnvConfigRestarter = (*EnvConfig)(nil)
 
 func (e *EnvConfig) SetComponent(component string) {
 	e.Component = component
@@ -189,6 +217,10 @@ func (e *EnvConfig) GetLeaderElectionConfig() (*kle.ComponentConfig, error) {
 	return &config, nil
 }
 
+func (e *EnvConfig) GetRestartBackoff() (time.Duration, time.Duration) {
+	return e.RestartBackoffMax, e.RestartStablePeriod
+}
+
 func defaultLeaderElectionConfig() *kle.ComponentConfig {
 	return &kle.ComponentConfig{
 		Buckets:       1,
@@ -16,8 +16,11 @@ limitations under the License.
 package adapter
 
 import (
+	"context"
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
@@ -35,6 +38,7 @@ func TestEnvConfig(t *testing.T) {
 	os.Setenv("K_LOGGING_CONFIG", "logging")
 	os.Setenv("K_TRACING_CONFIG", "tracing")
 	os.Setenv("K_LEADER_ELECTION_CONFIG", "leaderelection")
+	os.Setenv("RESTART_BACKOFF_MAX", "30s")
 	os.Setenv("MODE", "mymode") // note: custom to this test impl
 
 	defer func() {
@@ -44,6 +48,7 @@ func TestEnvConfig(t *testing.T) {
 		os.Unsetenv("K_LOGGING_CONFIG")
 		os.Unsetenv("K_TRACING_CONFIG")
 		os.Unsetenv("K_LEADER_ELECTION_CONFIG")
+		os.Unsetenv("RESTART_BACKOFF_MAX")
 		os.Unsetenv("MODE")
 	}()
 
@@ -65,4 +70,25 @@ func TestEnvConfig(t *testing.T) {
 		t.Errorf("Expected LeaderElectionConfigJson leaderelection, got: %s", env.LeaderElectionConfigJson)
 	}
 
+	if max, stable := env.GetRestartBackoff(); max != 30*time.Second || stable != time.Minute {
+		t.Errorf("Expected restart backoff 30s stable after 1m, got: %v stable after %v", max, stable)
+	}
+
+}
+
+type invalidEnvConfig struct {
+	EnvConfig
+}
+
+func (*invalidEnvConfig) Validate(context.Context) error {
+	return errors.New("invalid")
+}
+
+func TestValidateEnv(t *testing.T) {
+	if err := validateEnv(context.Background(), &myEnvConfig{}); err != nil {
+		t.Errorf("Expected no error without a Validate method: %v", err)
+	}
+	if err := validateEnv(context.Background(), &invalidEnvConfig{}); err == nil {
+		t.Error("Expected the Validate error")
+	}
 }
@@ -95,7 +95,7 @@ func Main(component string, ector EnvConfigConstructor, ctor AdapterConstructor)
 }
 
 func MainWithContext(ctx context.Context, component string, ector EnvConfigConstructor, ctor AdapterConstructor) {
-	MainWithEnv(ctx, component, ConstructEnvOrDie(ector), ctor)
+	MainWithEnv(ctx, component, constructEnvOrDie(ctx, ector), ctor)
 }
 
 func MainWithEnv(ctx context.Context, component string, env EnvConfigAccessor, ctor AdapterConstructor) {
@@ -182,7 +182,8 @@ func MainWithInformers(ctx context.Context, component string, env EnvConfigAcces
 		logger.Error("Error loading cloudevents overrides", zap.Error(err))
 	}
 
-	eventsClient, err := NewCloudEventsClientCRStatus(env.GetSink(), ceOverrides, reporter, crStatusEventClient)
+	wrapper, _ := env.(RoundTripperWrapper)
+	eventsClient, err := newCloudEventsClient(env.GetSink(), ceOverrides, reporter, crStatusEventClient, wrapper)
 	if err != nil {
 		logger.Fatal("Error building cloud event client", zap.Error(err))
 	}
@@ -195,6 +196,7 @@ func MainWithInformers(ctx context.Context, component string, env EnvConfigAcces
 
 	// Configuring the adapter
 	adapter := ctor(ctx, env, eventsClient)
+	adapter = withRestartBackoff(adapter, env)
 
 	// Start config watcher if enabled.
 	if IsConfigMapWatcherEnabled(ctx) {
@@ -224,13 +226,28 @@ func MainWithInformers(ctx context.Context, component string, env EnvConfigAcces
 }
 
 func ConstructEnvOrDie(ector EnvConfigConstructor) EnvConfigAccessor {
+	return constructEnvOrDie(context.Background(), ector)
+}
+
+func constructEnvOrDie(ctx context.Context, ector EnvConfigConstructor) EnvConfigAccessor {
 	env := ector()
 	if err := envconfig.Process("", env); err != nil {
 		log.Fatalf("Error processing env var: %s", err)
 	}
+	if err := validateEnv(ctx, env); err != nil {
+		log.Fatalf("Error validating env var: %s", err)
+	}
 	return env
 }
 
+// validateEnv validates env if it implements EnvConfigValidator.
+func validateEnv(ctx context.Context, env EnvConfigAccessor) error {
+	if v, ok := env.(EnvConfigValidator); ok {
+		return v.Validate(ctx)
+	}
+	return nil
+}
+
 func SetupInformers(ctx context.Context, logger *zap.SugaredLogger) (context.Context, []controller.Informer) {
 	// Run the injectors, but only if strictly necessary to relax the dependency on kubeconfig.
 	if len(injection.Default.GetInformers()) > 0 || len(injection.Default.GetClients()) > 0 ||
@@ -19,11 +19,9 @@ package adapter
 import (
 	"context"
 	"flag"
-	"log"
 	"net/http"
 	"time"
 
-	"github.com/kelseyhightower/envconfig"
 	"go.opencensus.io/stats/view"
 	"go.uber.org/zap"
 	"knative.dev/eventing/pkg/leaderelection"
@@ -52,10 +50,7 @@ func MainMessageAdapter(component string, ector EnvConfigConstructor, ctor Messa
 func MainMessageAdapterWithContext(ctx context.Context, component string, ector EnvConfigConstructor, ctor MessageAdapterConstructor) {
 	flag.Parse()
 
-	env := ector()
-	if err := envconfig.Process("", env); err != nil {
-		log.Fatalf("Error processing env var: %s", err)
-	}
+	env := constructEnvOrDie(ctx, ector)
 
 	// Retrieve the logger from the env
 	logger := env.GetLogger()
@@ -117,6 +112,7 @@ func MainMessageAdapterWithContext(ctx context.Context, component string, ector
 
 	// Configuring the adapter
 	adapter := ctor(ctx, env, httpBindingsSender, reporter)
+	adapter = withRestartBackoff(adapter, env)
 
 	// Build the leader elector
 	leConfig, err := env.GetLeaderElectionConfig()
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/logging"
+)
+
+// restartBackoffInitial is the backoff before the first restart.
+const restartBackoffInitial = time.Second
+
+// restartingAdapter restarts an adapter whose Start returns an error, after
+// an exponential backoff capped to max. The backoff is reset when the
+// adapter ran for at least stable before failing.
+type restartingAdapter struct {
+	adapter Adapter
+	max     time.Duration
+	stable  time.Duration
+
+	clock clock.Clock
+	// sleep waits for d, returning false if ctx is done first.
+	sleep func(ctx context.Context, d time.Duration) bool
+}
+
+var _ Adapter = (*restartingAdapter)(nil)
+
+func newRestartingAdapter(adapter Adapter, max, stable time.Duration) *restartingAdapter {
+	r := &restartingAdapter{
+		adapter: adapter,
+		max:     max,
+		stable:  stable,
+		clock:   clock.RealClock{},
+	}
+	r.sleep = r.clockSleep
+	return r
+}
+
+// withRestartBackoff returns adapter restarted with the backoff of env,
+// if env implements EnvConfigRestarter.
+func withRestartBackoff(adapter Adapter, env EnvConfigAccessor) Adapter {
+	if r, ok := env.(EnvConfigRestarter); ok {
+		if max, stable := r.GetRestartBackoff(); max > 0 {
+			return newRestartingAdapter(adapter, max, stable)
+		}
+	}
+	return adapter
+}
+
+// Start implements Adapter
+func (r *restartingAdapter) Start(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	backoff := restartBackoffInitial
+	for {
+		started := r.clock.Now()
+		err := r.adapter.Start(ctx)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		if r.clock.Since(started) >= r.stable {
+			backoff = restartBackoffInitial
+		}
+		if backoff > r.max {
+			backoff = r.max
+		}
+
+		logger.Warnw("Start returned an error, restarting", zap.Error(err), zap.Duration("backoff", backoff))
+		if !r.sleep(ctx, backoff) {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (r *restartingAdapter) clockSleep(ctx context.Context, d time.Duration) bool {
+	t := r.clock.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
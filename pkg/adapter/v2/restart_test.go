@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// failingAdapter fails the first len(runs) starts after running for the
+// matching duration, then succeeds.
+type failingAdapter struct {
+	clock  *clock.FakeClock
+	runs   []time.Duration
+	starts int
+}
+
+func (a *failingAdapter) Start(ctx context.Context) error {
+	defer func() { a.starts++ }()
+	if a.starts >= len(a.runs) {
+		return nil
+	}
+	a.clock.Step(a.runs[a.starts])
+	return errors.New("failed")
+}
+
+func TestRestartingAdapter(t *testing.T) {
+	testCases := map[string]struct {
+		max   time.Duration
+		runs  []time.Duration
+		wants []time.Duration
+	}{
+		"immediate failures": {
+			max:   10 * time.Second,
+			runs:  []time.Duration{0, 0, 0, 0, 0, 0},
+			wants: []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+		"reset after stable run": {
+			max:   10 * time.Second,
+			runs:  []time.Duration{0, 0, 0, time.Minute, 0},
+			wants: []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 1 * time.Second, 2 * time.Second},
+		},
+		"max below initial": {
+			max:   500 * time.Millisecond,
+			runs:  []time.Duration{0, 0},
+			wants: []time.Duration{500 * time.Millisecond, 500 * time.Millisecond},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			fc := clock.NewFakeClock(time.Now())
+			inner := &failingAdapter{clock: fc, runs: tc.runs}
+
+			var got []time.Duration
+			r := newRestartingAdapter(inner, tc.max, time.Minute)
+			r.clock = fc
+			r.sleep = func(_ context.Context, d time.Duration) bool {
+				got = append(got, d)
+				return true
+			}
+
+			if err := r.Start(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wants, got); diff != "" {
+				t.Errorf("unexpected backoffs (-want, +got) = %v", diff)
+			}
+			if want := len(tc.runs) + 1; inner.starts != want {
+				t.Errorf("Expected %d starts, got %d", want, inner.starts)
+			}
+		})
+	}
+}
+
+func TestRestartingAdapterCancelled(t *testing.T) {
+	inner := &failingAdapter{clock: clock.NewFakeClock(time.Now()), runs: []time.Duration{0, 0}}
+	r := newRestartingAdapter(inner, time.Minute, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.sleep = func(context.Context, time.Duration) bool {
+		cancel()
+		return false
+	}
+
+	if err := r.Start(ctx); err == nil {
+		t.Error("Expected the last Start error")
+	}
+	if inner.starts != 1 {
+		t.Errorf("Expected 1 start, got %d", inner.starts)
+	}
+}
+
+func TestWithRestartBackoff(t *testing.T) {
+	adapter := &failingAdapter{}
+	testCases := map[string]struct {
+		env     EnvConfigAccessor
+		restart bool
+	}{
+		"backoff": {
+			env:     &EnvConfig{RestartBackoffMax: time.Minute},
+			restart: true,
+		},
+		"no backoff": {
+			env: &EnvConfig{},
+		},
+		"no restarter": {
+			env: struct{ EnvConfigAccessor }{&EnvConfig{RestartBackoffMax: time.Minute}},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, restart := withRestartBackoff(adapter, tc.env).(*restartingAdapter)
+			if restart != tc.restart {
+				t.Errorf("Expected restarting adapter %v, got %v", tc.restart, restart)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// SendErrorClass is the category of a failure to send an event, for
+// alerting on the categories differently.
+type SendErrorClass string
+
+const (
+	// SendErrorConnection is a failure to resolve or connect to the sink,
+	// or a connection lost before its response.
+	SendErrorConnection SendErrorClass = "connection"
+	// SendErrorTimeout is a send timing out, before or after connecting.
+	SendErrorTimeout SendErrorClass = "timeout"
+	// SendErrorTLS is a failure of the TLS handshake, or of the
+	// verification of the certificate of the sink.
+	SendErrorTLS SendErrorClass = "tls"
+	// SendErrorClient is a 4xx response of the sink.
+	SendErrorClient SendErrorClass = "4xx"
+	// SendErrorServer is a 5xx response of the sink.
+	SendErrorServer SendErrorClass = "5xx"
+	// SendErrorOther is any other failure, such as an invalid event.
+	SendErrorOther SendErrorClass = "other"
+	// SendErrorUnknown is a failure without a cause, such as retries
+	// without their last result.
+	SendErrorUnknown SendErrorClass = "unknown"
+)
+
+// ClassifySendError returns the class of the failure of a send, from the
+// result returned by the Send and Request of a CloudEvents client. It
+// returns the empty class for an acknowledged result.
+func ClassifySendError(result protocol.Result) SendErrorClass {
+	if protocol.IsACK(result) {
+		return ""
+	}
+
+	// The retries result does not unwrap its last result.
+	var retries *http.RetriesResult
+	for errors.As(result, &retries) && retries.Result != result {
+		if retries.Result == nil {
+			return SendErrorUnknown
+		}
+		result = retries.Result
+	}
+
+	var res *http.Result
+	if errors.As(result, &res) {
+		switch res.StatusCode / 100 {
+		case 4:
+			return SendErrorClient
+		case 5:
+			return SendErrorServer
+		default:
+			return SendErrorOther
+		}
+	}
+
+	var netErr net.Error
+	if errors.Is(result, context.DeadlineExceeded) || (errors.As(result, &netErr) && netErr.Timeout()) {
+		return SendErrorTimeout
+	}
+
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		invalidCert      x509.CertificateInvalidError
+		hostname         x509.HostnameError
+		recordHeader     tls.RecordHeaderError
+	)
+	if errors.As(result, &unknownAuthority) || errors.As(result, &invalidCert) ||
+		errors.As(result, &hostname) || errors.As(result, &recordHeader) ||
+		// The TLS alerts are not exported.
+		strings.Contains(result.Error(), "tls: ") {
+		return SendErrorTLS
+	}
+
+	var (
+		dnsErr *net.DNSError
+		opErr  *net.OpError
+		urlErr *url.Error
+	)
+	if errors.As(result, &dnsErr) || errors.As(result, &opErr) || errors.As(result, &urlErr) {
+		return SendErrorConnection
+	}
+	return SendErrorOther
+}
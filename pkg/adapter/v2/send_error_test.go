@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func TestClassifySendError(t *testing.T) {
+	// transport wraps err like the client does a failed request.
+	transport := func(err error) protocol.Result {
+		return protocol.NewReceipt(false, "%w", &url.Error{Op: "Post", URL: "http://sink.example.com", Err: err})
+	}
+
+	testCases := map[string]struct {
+		result protocol.Result
+		want   SendErrorClass
+	}{
+		"ack": {},
+		"accepted": {
+			result: http.NewResult(202, "%w", protocol.ResultACK),
+		},
+		"not found": {
+			result: http.NewResult(404, "%w", protocol.ResultNACK),
+			want:   SendErrorClient,
+		},
+		"unavailable": {
+			result: http.NewResult(503, "%w", protocol.ResultNACK),
+			want:   SendErrorServer,
+		},
+		"unavailable after retries": {
+			result: http.NewRetriesResult(http.NewResult(503, "%w", protocol.ResultNACK), 2, time.Now(), nil),
+			want:   SendErrorServer,
+		},
+		"retries without result": {
+			result: &http.RetriesResult{Retries: 2},
+			want:   SendErrorUnknown,
+		},
+		"redirect": {
+			result: http.NewResult(302, "%w", protocol.ResultNACK),
+			want:   SendErrorOther,
+		},
+		"connection refused": {
+			result: transport(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}),
+			want:   SendErrorConnection,
+		},
+		"unknown host": {
+			result: transport(&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Name: "sink.example.com", IsNotFound: true}}),
+			want:   SendErrorConnection,
+		},
+		"dns timeout": {
+			result: transport(&net.DNSError{Name: "sink.example.com", IsTimeout: true}),
+			want:   SendErrorTimeout,
+		},
+		"deadline exceeded": {
+			result: transport(context.DeadlineExceeded),
+			want:   SendErrorTimeout,
+		},
+		"unknown authority": {
+			result: transport(x509.UnknownAuthorityError{}),
+			want:   SendErrorTLS,
+		},
+		"hostname mismatch": {
+			result: transport(x509.HostnameError{Certificate: &x509.Certificate{}, Host: "sink.example.com"}),
+			want:   SendErrorTLS,
+		},
+		"tls alert": {
+			result: transport(&net.OpError{Op: "remote error", Err: errors.New("tls: bad certificate")}),
+			want:   SendErrorTLS,
+		},
+		"invalid event": {
+			result: fmt.Errorf("event validation failed: %w", errors.New("id: MUST be a non-empty string")),
+			want:   SendErrorOther,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if got := ClassifySendError(tc.result); got != tc.want {
+				t.Errorf("ClassifySendError(%v) = %q, want %q", tc.result, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClassifySendErrorClient(t *testing.T) {
+	untrusted := httptest.NewTLSServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, _ *nethttp.Request) {
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer untrusted.Close()
+	closed := httptest.NewServer(nethttp.NotFoundHandler())
+	closed.Close()
+
+	testCases := map[string]struct {
+		target string
+		want   SendErrorClass
+	}{
+		"untrusted certificate": {
+			target: untrusted.URL,
+			want:   SendErrorTLS,
+		},
+		"closed server": {
+			target: closed.URL,
+			want:   SendErrorConnection,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p, err := cloudevents.NewHTTP(cloudevents.WithTarget(tc.target))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := cloudevents.NewClient(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			event := cloudevents.NewEvent()
+			event.SetID("1")
+			event.SetType("dev.knative.test")
+			event.SetSource("/test")
+
+			if got := ClassifySendError(c.Send(context.Background(), event)); got != tc.want {
+				t.Errorf("Expected the send failure class %q, got %q", tc.want, got)
+			}
+		})
+	}
+}